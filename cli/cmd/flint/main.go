@@ -4,16 +4,27 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/alecthomas/kong"
+	"github.com/anibalnet/blackbeard/cli/internal/audit"
 	"github.com/anibalnet/blackbeard/cli/internal/backup"
 	"github.com/anibalnet/blackbeard/cli/internal/cleanup"
 	"github.com/anibalnet/blackbeard/cli/internal/config"
 	dkr "github.com/anibalnet/blackbeard/cli/internal/docker"
+	"github.com/anibalnet/blackbeard/cli/internal/envfile"
+	"github.com/anibalnet/blackbeard/cli/internal/fleet"
 	"github.com/anibalnet/blackbeard/cli/internal/hw"
+	"github.com/anibalnet/blackbeard/cli/internal/metrics"
+	"github.com/anibalnet/blackbeard/cli/internal/remote"
+	"github.com/anibalnet/blackbeard/cli/internal/server"
 	"github.com/anibalnet/blackbeard/cli/internal/stack"
+	"github.com/anibalnet/blackbeard/cli/internal/top"
 	"github.com/anibalnet/blackbeard/cli/internal/ui"
+	"github.com/docker/go-units"
 )
 
 var (
@@ -25,6 +36,11 @@ var (
 // CLI is the root command structure for flint.
 type CLI struct {
 	ProjectDir string           `help:"Path to blackbeard project root." short:"p" env:"BLACKBEARD_DIR" type:"path"`
+	Project    string           `help:"Blackbeard instance to manage; uses .env.<name> and <name> as the compose project name. Set FLINT_PORT_OFFSET in .env.<name> to run it beside another instance." env:"FLINT_PROJECT"`
+	Files      []string         `help:"Compose file to use; repeat to merge several. Defaults to COMPOSE_FILE or docker-compose.yml plus any override file." short:"f" name:"file" sep:"none" type:"existingfile"`
+	Host       string           `help:"Docker daemon to manage, e.g. ssh://pi@blackbeard. Host-level commands run flint on it over SSH." short:"H" env:"DOCKER_HOST"`
+	Context    string           `help:"Docker context to use instead of --host." env:"DOCKER_CONTEXT"`
+	Hosts      string           `help:"Run on inventory hosts: all, or comma separated host and group names." env:"FLINT_HOSTS"`
 	NoColor    bool             `help:"Disable colored output." env:"NO_COLOR"`
 	Yes        bool             `help:"Skip confirmation prompts." short:"y"`
 	Version    kong.VersionFlag `help:"Show version."`
@@ -33,6 +49,11 @@ type CLI struct {
 	Backup BackupCmd `cmd:"" help:"Volume backup and restore operations."`
 	Docker DockerCmd `cmd:"" help:"Docker cleanup operations."`
 	Hw     HwCmd     `cmd:"" help:"Hardware monitoring (temperature, GPU/VPU)."`
+	Env    EnvCmd    `cmd:"" help:"Inspect, edit and validate the .env file."`
+
+	History HistoryCmd `cmd:"" help:"Show the audit log of mutating operations."`
+	Serve   ServeCmd   `cmd:"" help:"Serve the web dashboard (proxied by nginx at /flint/)."`
+	Top     TopCmd     `cmd:"" help:"Interactive terminal dashboard."`
 }
 
 // Ctx is the shared context passed to all command Run methods via Kong bindings.
@@ -60,18 +81,32 @@ type StackCmd struct {
 	Resources StackResourcesCmd `cmd:"" help:"Show resource usage (CPU, memory)."`
 	Validate  StackValidateCmd  `cmd:"" help:"Validate docker-compose configuration."`
 	Dirs      StackDirsCmd      `cmd:"" help:"Check config directories and create missing ones."`
+	Config    StackConfigCmd    `cmd:"" help:"Print the merged and interpolated compose project."`
+	Enable    StackEnableCmd    `cmd:"" help:"Enable previously disabled services."`
+	Disable   StackDisableCmd   `cmd:"" help:"Disable services so start, update and dirs skip them."`
 }
 
-type StackInstallCmd struct{}
+type StackInstallCmd struct {
+	Answers string `help:"YAML answers file for a non-interactive install. With a remote --host it is read on the board."`
+	Report  string `help:"Write a JSON install report to this file ('-' for stdout). Requires --answers."`
+}
 
 func (cmd *StackInstallCmd) Run(ctx *Ctx) error {
+	if cmd.Answers != "" {
+		return stack.RunInstallAnswers(ctx.Context, ctx.Config, ctx.Clients, ctx.Printer, cmd.Answers, cmd.Report)
+	}
+	if cmd.Report != "" {
+		return fmt.Errorf("--report requires --answers")
+	}
 	return stack.RunInstall(ctx.Context, ctx.Config, ctx.Clients, ctx.Printer, ctx.Yes)
 }
 
-type StackCheckCmd struct{}
+type StackCheckCmd struct {
+	FixDevices bool `help:"Write corrected Jellyfin device mappings to the compose override."`
+}
 
 func (cmd *StackCheckCmd) Run(ctx *Ctx) error {
-	return stack.RunCheck(ctx.Context, ctx.Config, ctx.Clients, ctx.Printer)
+	return stack.RunCheck(ctx.Context, ctx.Config, ctx.Clients, ctx.Printer, cmd.FixDevices)
 }
 
 type StackUninstallCmd struct{}
@@ -128,11 +163,12 @@ func (cmd *StackLogsCmd) Run(ctx *Ctx) error {
 }
 
 type StackUpdateCmd struct {
-	Service string `arg:"" optional:"" help:"Service to update. If omitted, updates entire stack."`
+	Service        string `arg:"" optional:"" help:"Service to update. If omitted, updates entire stack."`
+	SkipSpaceCheck bool   `help:"Pull even when the Docker disk is within the FLINT_DISK_RESERVE."`
 }
 
 func (cmd *StackUpdateCmd) Run(ctx *Ctx) error {
-	return stack.RunUpdate(ctx.Context, ctx.Config, ctx.Clients, ctx.Printer, cmd.Service)
+	return stack.RunUpdate(ctx.Context, ctx.Config, ctx.Clients, ctx.Printer, cmd.Service, cmd.SkipSpaceCheck)
 }
 
 type StackResourcesCmd struct{}
@@ -153,6 +189,31 @@ func (cmd *StackDirsCmd) Run(ctx *Ctx) error {
 	return stack.RunDirs(ctx.Context, ctx.Config, ctx.Printer)
 }
 
+type StackConfigCmd struct {
+	Format   string `help:"Output format." enum:"yaml,json" default:"yaml"`
+	Services bool   `help:"Only list the enabled service names."`
+}
+
+func (cmd *StackConfigCmd) Run(ctx *Ctx) error {
+	return stack.RunConfig(ctx.Context, ctx.Config, ctx.Printer, cmd.Format, cmd.Services)
+}
+
+type StackEnableCmd struct {
+	Services []string `arg:"" help:"Services to enable."`
+}
+
+func (cmd *StackEnableCmd) Run(ctx *Ctx) error {
+	return stack.RunEnable(ctx.Context, ctx.Config, ctx.Printer, cmd.Services)
+}
+
+type StackDisableCmd struct {
+	Services []string `arg:"" help:"Services to disable."`
+}
+
+func (cmd *StackDisableCmd) Run(ctx *Ctx) error {
+	return stack.RunDisable(ctx.Context, ctx.Config, ctx.Clients, ctx.Printer, cmd.Services)
+}
+
 // --- Backup commands ---
 
 type BackupCmd struct {
@@ -167,7 +228,7 @@ type BackupCmd struct {
 type BackupVolumesCmd struct{}
 
 func (cmd *BackupVolumesCmd) Run(ctx *Ctx) error {
-	return backup.RunListVolumes(ctx.Context, ctx.Clients, ctx.Printer)
+	return backup.RunListVolumes(ctx.Context, ctx.Config, ctx.Clients, ctx.Printer)
 }
 
 type BackupAllCmd struct{}
@@ -217,6 +278,9 @@ type DockerCmd struct {
 	PruneOld  DockerPruneOldCmd  `cmd:"prune-old" help:"Remove images older than N days."`
 	Clean     DockerCleanCmd     `cmd:"" help:"Complete cleanup (containers, networks, images, cache)."`
 	Protected DockerProtectedCmd `cmd:"" help:"Show protected images (in use by containers)."`
+	LogsUsage DockerLogsUsageCmd `cmd:"" help:"Show container log file sizes."`
+	LogsTrim  DockerLogsTrimCmd  `cmd:"" help:"Truncate container log files, optionally archiving them first."`
+	LogsLimit DockerLogsLimitCmd `cmd:"" help:"Limit log size of all services through the compose override."`
 }
 
 type DockerDiskCmd struct{}
@@ -225,36 +289,78 @@ func (cmd *DockerDiskCmd) Run(ctx *Ctx) error {
 	return cleanup.RunDisk(ctx.Context, ctx.Clients, ctx.Printer)
 }
 
+type DockerLogsUsageCmd struct{}
+
+func (cmd *DockerLogsUsageCmd) Run(ctx *Ctx) error {
+	return cleanup.RunLogsUsage(ctx.Context, ctx.Clients, ctx.Printer)
+}
+
+type DockerLogsTrimCmd struct {
+	Containers []string `arg:"" optional:"" help:"Containers to trim (default: all)."`
+	MinSize    string   `help:"Only trim logs at least this big, e.g. 100m." default:"0"`
+	Archive    string   `help:"Gzip each log into this directory before truncating it." type:"path"`
+}
+
+func (cmd *DockerLogsTrimCmd) Run(ctx *Ctx) error {
+	minSize, err := units.RAMInBytes(cmd.MinSize)
+	if err != nil {
+		return fmt.Errorf("invalid --min-size: %w", err)
+	}
+	return cleanup.RunLogsTrim(ctx.Context, ctx.Clients, ctx.Printer, cmd.Containers, minSize, cmd.Archive, ctx.Yes)
+}
+
+type DockerLogsLimitCmd struct {
+	MaxSize string `help:"Rotate a container's log when it reaches this size." default:"10m"`
+	MaxFile int    `help:"Number of log files to keep per container." default:"3"`
+}
+
+func (cmd *DockerLogsLimitCmd) Run(ctx *Ctx) error {
+	if _, err := units.RAMInBytes(cmd.MaxSize); err != nil {
+		return fmt.Errorf("invalid --max-size: %w", err)
+	}
+	if cmd.MaxFile < 1 {
+		return fmt.Errorf("--max-file must be at least 1")
+	}
+	return stack.RunLogsLimit(ctx.Context, ctx.Config, ctx.Printer, cmd.MaxSize, cmd.MaxFile)
+}
+
 type DockerListCmd struct{}
 
 func (cmd *DockerListCmd) Run(ctx *Ctx) error {
 	return cleanup.RunListImages(ctx.Context, ctx.Clients, ctx.Printer)
 }
 
-type DockerDanglingCmd struct{}
+type DockerDanglingCmd struct {
+	DryRun bool `help:"List what would be removed and the space freed, without removing anything."`
+}
 
 func (cmd *DockerDanglingCmd) Run(ctx *Ctx) error {
-	return cleanup.RunDangling(ctx.Context, ctx.Clients, ctx.Printer)
+	return cleanup.RunDangling(ctx.Context, ctx.Clients, ctx.Printer, cmd.DryRun)
 }
 
-type DockerPruneCmd struct{}
+type DockerPruneCmd struct {
+	DryRun bool `help:"List what would be removed and the space freed, without removing anything."`
+}
 
 func (cmd *DockerPruneCmd) Run(ctx *Ctx) error {
-	return cleanup.RunPruneImages(ctx.Context, ctx.Clients, ctx.Printer, ctx.Yes)
+	return cleanup.RunPruneImages(ctx.Context, ctx.Clients, ctx.Printer, ctx.Yes, cmd.DryRun)
 }
 
 type DockerPruneOldCmd struct {
-	Days int `arg:"" optional:"" default:"30" help:"Remove images older than this many days."`
+	Days   int  `arg:"" optional:"" default:"30" help:"Remove images older than this many days."`
+	DryRun bool `help:"List what would be removed and the space freed, without removing anything."`
 }
 
 func (cmd *DockerPruneOldCmd) Run(ctx *Ctx) error {
-	return cleanup.RunPruneOld(ctx.Context, ctx.Clients, ctx.Printer, cmd.Days, ctx.Yes)
+	return cleanup.RunPruneOld(ctx.Context, ctx.Clients, ctx.Printer, cmd.Days, ctx.Yes, cmd.DryRun)
 }
 
-type DockerCleanCmd struct{}
+type DockerCleanCmd struct {
+	DryRun bool `help:"List what would be removed and the space freed, without removing anything."`
+}
 
 func (cmd *DockerCleanCmd) Run(ctx *Ctx) error {
-	return cleanup.RunCleanAll(ctx.Context, ctx.Clients, ctx.Printer, ctx.Yes)
+	return cleanup.RunCleanAll(ctx.Context, ctx.Clients, ctx.Printer, ctx.Yes, cmd.DryRun)
 }
 
 type DockerProtectedCmd struct{}
@@ -266,16 +372,30 @@ func (cmd *DockerProtectedCmd) Run(ctx *Ctx) error {
 // --- Hardware monitoring commands ---
 
 type HwCmd struct {
-	Cpu         HwCpuCmd         `cmd:"" help:"Show CPU model, cores, and usage."`
-	Mem         HwMemCmd         `cmd:"" help:"Show RAM and swap usage."`
-	Disk        HwDiskCmd        `cmd:"" help:"Show disk usage for specified paths."`
-	Net         HwNetCmd         `cmd:"" help:"Show network I/O per interface."`
-	Info        HwInfoCmd        `cmd:"" help:"Show host information (OS, kernel, uptime, load)."`
-	Temp        HwTempCmd        `cmd:"" help:"Show temperature (CPU, GPU, or both)."`
-	TempMonitor HwTempMonitorCmd `cmd:"temp-monitor" help:"Monitor temperature continuously."`
-	Gpu         HwGpuCmd         `cmd:"" help:"Show GPU/VPU status."`
-	GpuMonitor  HwGpuMonitorCmd  `cmd:"gpu-monitor" help:"Monitor GPU/VPU continuously."`
-	Status      HwStatusCmd      `cmd:"" help:"Show full hardware status."`
+	Cpu           HwCpuCmd         `cmd:"" help:"Show CPU model, cores, and usage."`
+	Mem           HwMemCmd         `cmd:"" help:"Show RAM and swap usage."`
+	Disk          HwDiskCmd        `cmd:"" help:"Show disk usage for specified paths."`
+	Net           HwNetCmd         `cmd:"" help:"Show network throughput per interface and service."`
+	Info          HwInfoCmd        `cmd:"" help:"Show host information (OS, kernel, uptime, load)."`
+	Temp          HwTempCmd        `cmd:"" help:"Show temperature (CPU, GPU, or both)."`
+	TempMonitor   HwTempMonitorCmd `cmd:"temp-monitor" help:"Monitor temperature continuously."`
+	Gpu           HwGpuCmd         `cmd:"" help:"Show GPU/VPU status."`
+	GpuMonitor    HwGpuMonitorCmd  `cmd:"gpu-monitor" help:"Monitor GPU/VPU continuously."`
+	Status        HwStatusCmd      `cmd:"" help:"Show full hardware status."`
+	History       HwHistoryCmd     `cmd:"" help:"Show recorded hardware history with sparklines."`
+	Smart         HwSmartCmd       `cmd:"" help:"Show disk health from SMART attributes."`
+	Io            HwIoCmd          `cmd:"" help:"Show disk throughput, latency and utilisation per device and container."`
+	TranscodeTest HwTranscodeCmd   `cmd:"" help:"Test hardware transcoding inside the Jellyfin container."`
+}
+
+type HwTranscodeCmd struct {
+	Service string `help:"Compose service running Jellyfin." default:"jellyfin"`
+	FFmpeg  string `name:"ffmpeg" help:"Path of jellyfin-ffmpeg inside the container." default:"/usr/lib/jellyfin-ffmpeg/ffmpeg"`
+	Seconds int    `help:"Length of the test sample in seconds." default:"10"`
+}
+
+func (cmd *HwTranscodeCmd) Run(ctx *Ctx) error {
+	return stack.RunTranscodeTest(ctx.Context, ctx.Config, ctx.Clients, ctx.Printer, cmd.Service, cmd.FFmpeg, cmd.Seconds)
 }
 
 type HwCpuCmd struct{}
@@ -291,17 +411,30 @@ func (cmd *HwMemCmd) Run(ctx *Ctx) error {
 }
 
 type HwDiskCmd struct {
-	Paths []string `arg:"" optional:"" help:"Paths to check. Defaults to / and /media/STORAGE if present."`
+	Paths []string `arg:"" optional:"" help:"Paths to check. Defaults to the stack's download, config, backup and Docker directories and /media mounts."`
 }
 
 func (cmd *HwDiskCmd) Run(ctx *Ctx) error {
-	return hw.RunDisk(ctx.Printer, cmd.Paths)
+	return hw.RunDisk(ctx.Printer, cmd.Paths, stack.StoragePaths(ctx.Context, ctx.Config, ctx.Clients))
 }
 
-type HwNetCmd struct{}
+type HwNetCmd struct {
+	Interval int  `help:"Sampling interval in seconds." default:"1"`
+	Watch    bool `short:"w" help:"Refresh continuously until Ctrl+C."`
+}
 
 func (cmd *HwNetCmd) Run(ctx *Ctx) error {
-	return hw.RunNet(ctx.Printer)
+	if cmd.Interval < 1 {
+		return fmt.Errorf("--interval must be at least 1")
+	}
+	var services hw.ServiceNetFunc
+	if containerNames(ctx) != nil {
+		services = func() map[string][2]uint64 {
+			totals, _ := stack.NetworkTotals(ctx.Context, ctx.Config, ctx.Clients)
+			return totals
+		}
+	}
+	return hw.RunNet(ctx.Context, ctx.Printer, cmd.Interval, cmd.Watch, services)
 }
 
 type HwInfoCmd struct{}
@@ -312,10 +445,15 @@ func (cmd *HwInfoCmd) Run(ctx *Ctx) error {
 
 type HwTempCmd struct {
 	Target string `arg:"" optional:"" default:"all" enum:"all,cpu,gpu" help:"Sensor to read: cpu, gpu, or all."`
+	Sensor string `help:"Show a single sensor by name or label (see: flint hw temp)."`
 }
 
 func (cmd *HwTempCmd) Run(ctx *Ctx) error {
-	return hw.RunTemp(ctx.Printer, cmd.Target)
+	sensors, err := hw.LoadSensorConfig(ctx.Config.SensorsFile)
+	if err != nil {
+		return err
+	}
+	return hw.RunTemp(ctx.Printer, cmd.Target, cmd.Sensor, sensors)
 }
 
 type HwTempMonitorCmd struct {
@@ -329,7 +467,7 @@ func (cmd *HwTempMonitorCmd) Run(ctx *Ctx) error {
 type HwGpuCmd struct{}
 
 func (cmd *HwGpuCmd) Run(ctx *Ctx) error {
-	return hw.RunGPUStatus(ctx.Printer)
+	return hw.RunGPUStatus(ctx.Printer, containerNames(ctx))
 }
 
 type HwGpuMonitorCmd struct {
@@ -343,7 +481,244 @@ func (cmd *HwGpuMonitorCmd) Run(ctx *Ctx) error {
 type HwStatusCmd struct{}
 
 func (cmd *HwStatusCmd) Run(ctx *Ctx) error {
-	return hw.RunFullStatus(ctx.Printer)
+	sensors, err := hw.LoadSensorConfig(ctx.Config.SensorsFile)
+	if err != nil {
+		return err
+	}
+	return hw.RunFullStatus(ctx.Printer, sensors, ctx.Config.StateDir,
+		stack.StoragePaths(ctx.Context, ctx.Config, ctx.Clients))
+}
+
+type HwIoCmd struct {
+	Interval int  `help:"Sampling interval in seconds." default:"1"`
+	Watch    bool `short:"w" help:"Refresh continuously until Ctrl+C."`
+}
+
+func (cmd *HwIoCmd) Run(ctx *Ctx) error {
+	if cmd.Interval < 1 {
+		return fmt.Errorf("--interval must be at least 1")
+	}
+	return hw.RunIO(ctx.Context, ctx.Printer, cmd.Interval, cmd.Watch, containerNames(ctx))
+}
+
+// containerNames returns a lookup of running container names for host-level
+// commands that attribute usage to containers, or nil without Docker.
+func containerNames(ctx *Ctx) func() map[string]string {
+	if ctx.Clients == nil {
+		return nil
+	}
+	if _, err := stack.ContainerNames(ctx.Context, ctx.Config, ctx.Clients); err != nil {
+		ctx.Printer.Warning(fmt.Sprintf("Per-container attribution unavailable: %s", err))
+		return nil
+	}
+	return func() map[string]string {
+		names, _ := stack.ContainerNames(ctx.Context, ctx.Config, ctx.Clients)
+		return names
+	}
+}
+
+type HwSmartCmd struct {
+	Devices []string `arg:"" optional:"" help:"Drives to check, e.g. /dev/sda (default: all SATA/USB drives)."`
+	All     bool     `help:"Show every attribute, not only the health-related ones."`
+	Ack     bool     `help:"Accept the current counters as the baseline, clearing warnings about counters that rose."`
+}
+
+func (cmd *HwSmartCmd) Run(ctx *Ctx) error {
+	return hw.RunSmart(ctx.Printer, cmd.Devices, ctx.Config.StateDir, cmd.All, cmd.Ack)
+}
+
+type HwHistoryCmd struct {
+	Metric string `arg:"" optional:"" help:"Metric or group (cpu, cpu.freq, mem, temp, temp.cpu, gpu.freq, vpu.irq, throttled). Default: all."`
+	Since  string `help:"How far back to look (e.g. 30m, 24h, 7d)." default:"24h"`
+	CSV    bool   `name:"csv" help:"Print samples as CSV instead of a summary."`
+	Width  int    `help:"Sparkline width in columns." default:"48"`
+}
+
+func (cmd *HwHistoryCmd) Run(ctx *Ctx) error {
+	if cmd.Width < 1 {
+		return fmt.Errorf("--width must be at least 1")
+	}
+	since, err := ui.ParseSince(cmd.Since)
+	if err != nil {
+		return err
+	}
+	return metrics.RunHistory(ctx.Config, ctx.Printer, cmd.Metric, since, cmd.CSV, cmd.Width)
+}
+
+// --- Env commands ---
+
+type EnvCmd struct {
+	Get      EnvGetCmd      `cmd:"" help:"Print the value of a variable."`
+	Set      EnvSetCmd      `cmd:"" help:"Set a variable, adding it if missing."`
+	Unset    EnvUnsetCmd    `cmd:"" help:"Remove a variable."`
+	Diff     EnvDiffCmd     `cmd:"" help:"Show keys missing from or extra to .env.example."`
+	Validate EnvValidateCmd `cmd:"" help:"Validate every variable referenced in docker-compose.yml."`
+}
+
+type EnvGetCmd struct {
+	Key string `arg:"" help:"Variable name."`
+}
+
+func (cmd *EnvGetCmd) Run(ctx *Ctx) error {
+	return envfile.RunGet(ctx.Context, ctx.Config, ctx.Printer, cmd.Key)
+}
+
+type EnvSetCmd struct {
+	Key   string `arg:"" help:"Variable name."`
+	Value string `arg:"" help:"New value."`
+}
+
+func (cmd *EnvSetCmd) Run(ctx *Ctx) error {
+	return envfile.RunSet(ctx.Context, ctx.Config, ctx.Printer, cmd.Key, cmd.Value)
+}
+
+type EnvUnsetCmd struct {
+	Key string `arg:"" help:"Variable name."`
+}
+
+func (cmd *EnvUnsetCmd) Run(ctx *Ctx) error {
+	return envfile.RunUnset(ctx.Context, ctx.Config, ctx.Printer, cmd.Key)
+}
+
+type EnvDiffCmd struct{}
+
+func (cmd *EnvDiffCmd) Run(ctx *Ctx) error {
+	return envfile.RunDiff(ctx.Context, ctx.Config, ctx.Printer)
+}
+
+type EnvValidateCmd struct{}
+
+func (cmd *EnvValidateCmd) Run(ctx *Ctx) error {
+	return envfile.RunValidate(ctx.Context, ctx.Config, ctx.Printer)
+}
+
+// --- History command ---
+
+type HistoryCmd struct {
+	Since   string `help:"Only show operations newer than this (e.g. 24h, 7d)." short:"s"`
+	Command string `help:"Only show operations whose command contains this text." short:"c"`
+	User    string `help:"Only show operations run by this user." short:"u"`
+	Failed  bool   `help:"Only show failed operations."`
+	Limit   int    `help:"Show at most this many of the most recent operations." short:"n" default:"50"`
+	JSON    bool   `help:"Print raw JSON lines instead of a table." name:"json"`
+}
+
+func (cmd *HistoryCmd) Run(ctx *Ctx) error {
+	return audit.RunHistory(ctx.Context, ctx.Config, ctx.Printer, audit.HistoryOptions{
+		Since:   cmd.Since,
+		Command: cmd.Command,
+		User:    cmd.User,
+		Failed:  cmd.Failed,
+		Limit:   cmd.Limit,
+		JSON:    cmd.JSON,
+	})
+}
+
+// --- Serve command ---
+
+type ServeCmd struct {
+	Listen   string        `help:"Address to listen on. Use the Docker bridge address (e.g. 172.17.0.1:8090) for nginx to proxy /flint/." default:"127.0.0.1:8090" env:"FLINT_LISTEN"`
+	Interval time.Duration `help:"How often to refresh dashboard data." default:"5s"`
+	History  bool          `help:"Record hardware metrics history (see flint hw history)." default:"true" negatable:""`
+	Guard    bool          `help:"Pause qBittorrent while the downloads disk is nearly full (FLINT_GUARD_PAUSE_PCT / FLINT_GUARD_RESUME_PCT)."`
+}
+
+func (cmd *ServeCmd) Run(ctx *Ctx) error {
+	if cmd.Interval < time.Second {
+		return fmt.Errorf("--interval must be at least 1s")
+	}
+	return server.RunServe(ctx.Context, ctx.Config, ctx.Clients, ctx.Printer, cmd.Listen, cmd.Interval, cmd.History, cmd.Guard)
+}
+
+type TopCmd struct {
+	Interval time.Duration `help:"Refresh interval." default:"2s"`
+}
+
+func (cmd *TopCmd) Run(ctx *Ctx) error {
+	if cmd.Interval < 100*time.Millisecond {
+		return fmt.Errorf("--interval must be at least 100ms")
+	}
+	return top.RunTop(ctx.Context, ctx.Config, ctx.Clients, ctx.Printer, cmd.Interval)
+}
+
+// usesDocker reports whether a host-level command can use Docker when it
+// is available, without requiring it.
+func usesDocker(cmd string) bool {
+	return cmd == "hw io" || cmd == "hw net" || cmd == "hw disk" || cmd == "hw status" || cmd == "hw gpu"
+}
+
+// isMutating reports whether a command changes the system and must be
+// recorded in the audit log.
+func isMutating(cmd string) bool {
+	switch cmd {
+	case "stack install", "stack uninstall", "stack dirs",
+		"stack start", "stack start <service>",
+		"stack stop", "stack stop <service>",
+		"stack restart", "stack restart <service>",
+		"stack update", "stack update <service>",
+		"backup all", "backup volume <name>", "backup cleanup", "backup cleanup <days>",
+		"backup restore <file>", "backup restore <file> <name>",
+		"docker dangling", "docker prune", "docker prune-old", "docker prune-old <days>", "docker clean",
+		"stack enable <services>", "stack disable <services>",
+		"env set <key> <value>", "env unset <key>",
+		"docker logs-trim", "docker logs-trim <containers>", "docker logs-limit":
+		return true
+	}
+	return false
+}
+
+// isHostLocal reports whether a command reads or writes the host filesystem
+// directly, and so must run on the remote board rather than via the Docker API.
+// Backups are included: the /backup bind mount is resolved by the daemon, so
+// the archives, their listing and the free-space check live on the board.
+// So are stack install, which creates the data directories, and stack check,
+// which inspects the board's video devices.
+func isHostLocal(cmd string) bool {
+	return strings.HasPrefix(cmd, "hw ") || (strings.HasPrefix(cmd, "backup ") && cmd != "backup volumes") ||
+		cmd == "stack dirs" || cmd == "stack install" || cmd == "stack check" ||
+		cmd == "docker logs-usage" || strings.HasPrefix(cmd, "docker logs-trim")
+}
+
+// runRemote re-runs the current command line with flint on the SSH host
+// behind the selected Docker endpoint and exits with its status.
+func runRemote(cfg *config.Config, printer *ui.Printer, host, cmd string) {
+	target, ok := remote.ParseSSH(host)
+	if !ok {
+		printer.Error(fmt.Sprintf("'%s' needs shell access to the board; use an ssh:// host (got %s)", cmd, host))
+		os.Exit(1)
+	}
+
+	args := remote.ForwardArgs(os.Args[1:])
+	if cfg.RemoteProjectDir != "" {
+		args = append([]string{"--project-dir", cfg.RemoteProjectDir}, args...)
+	}
+
+	code, err := target.Run(cfg.RemoteBin, args...)
+	if err != nil {
+		printer.Error(err.Error())
+	}
+	os.Exit(code)
+}
+
+// isInteractive reports whether a command streams until interrupted, which
+// cannot be aggregated across hosts.
+func isInteractive(cmd string) bool {
+	return strings.HasPrefix(cmd, "stack logs") || strings.HasSuffix(cmd, "-monitor") ||
+		strings.HasPrefix(cmd, "backup restore") || cmd == "serve" || cmd == "top" ||
+		slices.Contains(os.Args, "--watch") || slices.Contains(os.Args, "-w")
+}
+
+// runFleet fans the current command line out to inventory hosts and exits.
+func runFleet(cfg *config.Config, printer *ui.Printer, selector, cmd string) {
+	if isInteractive(cmd) {
+		printer.Error(fmt.Sprintf("'%s' is interactive and cannot run with --hosts", cmd))
+		os.Exit(1)
+	}
+	if err := fleet.RunFleet(context.Background(), cfg, printer, selector, os.Args[1:]); err != nil {
+		printer.Error(err.Error())
+		os.Exit(1)
+	}
+	os.Exit(0)
 }
 
 // --- main ---
@@ -358,13 +733,33 @@ func main() {
 	)
 
 	printer := ui.NewPrinter(cli.NoColor)
+	endpoint := dkr.Endpoint{Host: cli.Host, Context: cli.Context}
+	var dockerHost string
+	if cli.Host != "" || cli.Context != "" {
+		host, err := dkr.ResolveHost(endpoint)
+		if err != nil {
+			printer.Error(fmt.Sprintf("resolving Docker endpoint: %s", err))
+			os.Exit(1)
+		}
+		dockerHost = host
+	}
+	forward := isHostLocal(kongCtx.Command()) && !dkr.IsLocalHost(dockerHost)
+	multiHost := cli.Hosts != ""
+	if multiHost && (cli.Host != "" || cli.Context != "") {
+		printer.Error("--hosts cannot be combined with --host or --context")
+		os.Exit(1)
+	}
 
 	// Resolve project directory
 	projectDir, err := config.ResolveProjectDir(cli.ProjectDir)
+	if err != nil && cli.ProjectDir == "" && len(cli.Files) > 0 {
+		// Explicit compose files outside a blackbeard checkout: use the first file's directory
+		projectDir, err = filepath.Abs(filepath.Dir(cli.Files[0]))
+	}
 	if err != nil {
 		// hw commands don't need project dir
 		cmd := kongCtx.Command()
-		if !strings.HasPrefix(cmd, "hw ") {
+		if !strings.HasPrefix(cmd, "hw ") && !forward && !multiHost {
 			printer.Error(err.Error())
 			os.Exit(1)
 		}
@@ -373,43 +768,90 @@ func main() {
 	}
 
 	// Load configuration
-	cfg, err := config.Load(projectDir)
+	cfg, err := config.Load(projectDir, cli.Project)
 	if err != nil {
 		printer.Error(fmt.Sprintf("loading config: %s", err))
 		os.Exit(1)
 	}
 
+	if multiHost {
+		runFleet(cfg, printer, cli.Hosts, kongCtx.Command())
+	}
+	if forward {
+		runRemote(cfg, printer, dockerHost, kongCtx.Command())
+	}
+
+	if err := cfg.ResolveComposeFiles(cli.Files); err != nil && !strings.HasPrefix(kongCtx.Command(), "hw ") {
+		printer.Error(err.Error())
+		os.Exit(1)
+	}
+
 	// Initialize Docker clients (lazy - only when needed)
 	var clients *dkr.Clients
 	cmd := kongCtx.Command()
 
 	needsDocker := true
 	switch {
-	case strings.HasPrefix(cmd, "hw "),
-		cmd == "backup list", cmd == "backup cleanup",
-		cmd == "stack validate", cmd == "stack dirs":
+	case cmd == "hw transcode-test":
+		// Runs ffmpeg inside the Jellyfin container
+	case strings.HasPrefix(cmd, "hw "), strings.HasPrefix(cmd, "env "),
+		cmd == "backup list", strings.HasPrefix(cmd, "backup cleanup"),
+		cmd == "history",
+		cmd == "stack validate", cmd == "stack dirs", cmd == "stack enable <services>",
+		cmd == "stack config", cmd == "docker logs-limit":
 		needsDocker = false
 	}
 
 	if needsDocker {
-		clients, err = dkr.NewClients()
+		clients, err = dkr.NewClients(endpoint)
 		if err != nil {
 			printer.Error(fmt.Sprintf("connecting to Docker: %s", err))
 			os.Exit(1)
 		}
 		defer clients.Close()
+	} else if usesDocker(cmd) {
+		// Optional: only used to attribute usage to containers
+		if clients, err = dkr.NewClients(endpoint); err == nil {
+			defer clients.Close()
+		}
+	}
+
+	runCtx := context.Background()
+	if !strings.HasPrefix(cmd, "hw ") || needsDocker {
+		// Errors surface again in the commands that load the project
+		_ = stack.ResolveNames(runCtx, cfg)
+	}
+
+	var entry *audit.Entry
+	// stack check only changes the system when it rewrites the override
+	if isMutating(cmd) || (cmd == "stack check" && cli.Stack.Check.FixDevices) {
+		entry = audit.NewEntry(cmd, os.Args[1:])
+		entry.Project = cfg.ProjectName
+		if cmd == "env set <key> <value>" {
+			entry.Redact(cli.Env.Set.Value)
+		}
+		runCtx = audit.WithEntry(runCtx, entry)
 	}
 
 	ctx := &Ctx{
-		Context: context.Background(),
+		Context: runCtx,
 		Config:  cfg,
 		Clients: clients,
 		Printer: printer,
 		Yes:     cli.Yes,
 	}
 
-	if err := kongCtx.Run(ctx); err != nil {
-		printer.Error(err.Error())
+	runErr := kongCtx.Run(ctx)
+
+	if entry != nil {
+		entry.Finish(runErr)
+		if err := audit.Append(cfg.AuditLog, entry); err != nil {
+			printer.Warning(fmt.Sprintf("audit log: %s", err))
+		}
+	}
+
+	if runErr != nil {
+		printer.Error(runErr.Error())
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,241 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Result values recorded for an operation.
+const (
+	ResultSuccess   = "success"
+	ResultFailed    = "failed"
+	ResultCancelled = "cancelled"
+)
+
+// Entry is a single line of the audit journal.
+type Entry struct {
+	Time       time.Time `json:"time"`
+	User       string    `json:"user"`
+	Host       string    `json:"host,omitempty"`
+	Project    string    `json:"project,omitempty"`
+	Command    string    `json:"command"`
+	Args       []string  `json:"args"`
+	Services   []string  `json:"services,omitempty"`
+	Containers []string  `json:"containers,omitempty"`
+	Volumes    []string  `json:"volumes,omitempty"`
+	Images     []string  `json:"images,omitempty"`
+	Networks   []string  `json:"networks,omitempty"`
+	Notes      []string  `json:"notes,omitempty"`
+	Result     string    `json:"result"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"duration_ms"`
+
+	cancelled bool
+}
+
+// NewEntry starts an entry for the given kong command path and raw arguments.
+func NewEntry(command string, args []string) *Entry {
+	host, _ := os.Hostname()
+	return &Entry{
+		Time:    time.Now(),
+		User:    currentUser(),
+		Host:    host,
+		Command: command,
+		Args:    args,
+	}
+}
+
+// Redact replaces every argument equal to one of secrets, so values such
+// as passwords passed to env set never reach the journal.
+func (e *Entry) Redact(secrets ...string) {
+	args := make([]string, len(e.Args))
+	for i, arg := range e.Args {
+		args[i] = arg
+		for _, secret := range secrets {
+			if secret != "" && arg == secret {
+				args[i] = "<redacted>"
+			}
+		}
+	}
+	e.Args = args
+}
+
+// Finish stamps the result and duration based on the command's error.
+func (e *Entry) Finish(err error) {
+	e.DurationMs = time.Since(e.Time).Milliseconds()
+	switch {
+	case err != nil:
+		e.Result = ResultFailed
+		e.Error = err.Error()
+	case e.cancelled:
+		e.Result = ResultCancelled
+	default:
+		e.Result = ResultSuccess
+	}
+}
+
+// Affected returns a compact summary of everything the operation touched.
+func (e *Entry) Affected() string {
+	var parts []string
+	add := func(kind string, items []string) {
+		if len(items) > 0 {
+			parts = append(parts, fmt.Sprintf("%s: %s", kind, strings.Join(items, ",")))
+		}
+	}
+	add("services", e.Services)
+	add("containers", e.Containers)
+	add("volumes", e.Volumes)
+	add("images", e.Images)
+	add("networks", e.Networks)
+	add("notes", e.Notes)
+	return strings.Join(parts, "; ")
+}
+
+// currentUser prefers SUDO_USER so operations run through sudo are attributed
+// to the person who invoked it rather than root.
+func currentUser() string {
+	if sudoUser := os.Getenv("SUDO_USER"); sudoUser != "" {
+		return sudoUser
+	}
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return fmt.Sprintf("uid:%d", os.Getuid())
+}
+
+// Append writes the entry as one JSON line at the end of the journal.
+func Append(path string, e *Entry) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating audit dir: %w", err)
+	}
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("encoding audit entry: %w", err)
+	}
+
+	// Arguments and notes can name hosts and paths, so keep the journal private
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return fmt.Errorf("opening audit log: %w", err)
+	}
+	defer f.Close()
+	_ = f.Chmod(0600) // journals created before this were world-readable
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("writing audit log: %w", err)
+	}
+	return nil
+}
+
+// ReadAll returns every entry in the journal in file order.
+// Malformed lines are skipped so a truncated write never hides history.
+func ReadAll(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
+
+// --- context helpers ---
+
+type entryKey struct{}
+
+// WithEntry attaches an entry to ctx so Run functions can record what they touch.
+func WithEntry(ctx context.Context, e *Entry) context.Context {
+	return context.WithValue(ctx, entryKey{}, e)
+}
+
+// FromContext returns the entry attached to ctx, or nil when the command is not audited.
+func FromContext(ctx context.Context) *Entry {
+	e, _ := ctx.Value(entryKey{}).(*Entry)
+	return e
+}
+
+// AddServices records affected compose services.
+func AddServices(ctx context.Context, names ...string) {
+	if e := FromContext(ctx); e != nil {
+		e.Services = appendUnique(e.Services, names...)
+	}
+}
+
+// AddContainers records affected containers.
+func AddContainers(ctx context.Context, names ...string) {
+	if e := FromContext(ctx); e != nil {
+		e.Containers = appendUnique(e.Containers, names...)
+	}
+}
+
+// AddVolumes records affected volumes.
+func AddVolumes(ctx context.Context, names ...string) {
+	if e := FromContext(ctx); e != nil {
+		e.Volumes = appendUnique(e.Volumes, names...)
+	}
+}
+
+// AddImages records affected images.
+func AddImages(ctx context.Context, names ...string) {
+	if e := FromContext(ctx); e != nil {
+		e.Images = appendUnique(e.Images, names...)
+	}
+}
+
+// AddNetworks records affected networks.
+func AddNetworks(ctx context.Context, names ...string) {
+	if e := FromContext(ctx); e != nil {
+		e.Networks = appendUnique(e.Networks, names...)
+	}
+}
+
+// Note records a free-form change, such as a rewritten .env key.
+func Note(ctx context.Context, format string, args ...any) {
+	if e := FromContext(ctx); e != nil {
+		e.Notes = append(e.Notes, fmt.Sprintf(format, args...))
+	}
+}
+
+// Cancelled marks the operation as declined at a confirmation prompt.
+func Cancelled(ctx context.Context) {
+	if e := FromContext(ctx); e != nil {
+		e.cancelled = true
+	}
+}
+
+func appendUnique(list []string, items ...string) []string {
+	for _, item := range items {
+		if item == "" {
+			continue
+		}
+		found := false
+		for _, existing := range list {
+			if existing == item {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, item)
+		}
+	}
+	return list
+}
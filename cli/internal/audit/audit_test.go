@@ -0,0 +1,43 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	e := NewEntry("env set <key> <value>", []string{"env", "set", "API_KEY", "hunter2"})
+	e.Redact("hunter2", "")
+	want := []string{"env", "set", "API_KEY", "<redacted>"}
+	if !slices.Equal(e.Args, want) {
+		t.Errorf("Args = %v, want %v", e.Args, want)
+	}
+}
+
+func TestAppendIsPrivate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	// An older journal created world-readable is tightened on the next write
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := Append(path, NewEntry("stack stop", []string{"stack", "stop"})); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+	}
+	entries, err := ReadAll(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Command != "stack stop" {
+		t.Errorf("ReadAll = %+v, want one stack stop entry", entries)
+	}
+}
@@ -0,0 +1,109 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/anibalnet/blackbeard/cli/internal/config"
+	"github.com/anibalnet/blackbeard/cli/internal/ui"
+	"github.com/fatih/color"
+)
+
+// HistoryOptions filters the audit journal.
+type HistoryOptions struct {
+	Since   string // duration like "24h" or "7d"
+	Command string // substring match on the command path
+	User    string
+	Failed  bool
+	Limit   int
+	JSON    bool
+}
+
+// RunHistory prints audit journal entries, newest last.
+func RunHistory(_ context.Context, cfg *config.Config, p *ui.Printer, opts HistoryOptions) error {
+	entries, err := ReadAll(cfg.AuditLog)
+	if err != nil {
+		if os.IsNotExist(err) {
+			p.Warning(fmt.Sprintf("No audit log found at %s", cfg.AuditLog))
+			return nil
+		}
+		return fmt.Errorf("reading audit log: %w", err)
+	}
+
+	var cutoff time.Time
+	if opts.Since != "" {
+		d, err := ui.ParseSince(opts.Since)
+		if err != nil {
+			return err
+		}
+		cutoff = time.Now().Add(-d)
+	}
+
+	var filtered []Entry
+	for _, e := range entries {
+		if !cutoff.IsZero() && e.Time.Before(cutoff) {
+			continue
+		}
+		if opts.Command != "" && !strings.Contains(e.Command, opts.Command) {
+			continue
+		}
+		if opts.User != "" && e.User != opts.User {
+			continue
+		}
+		if opts.Failed && e.Result != ResultFailed {
+			continue
+		}
+		filtered = append(filtered, e)
+	}
+
+	if opts.Limit > 0 && len(filtered) > opts.Limit {
+		filtered = filtered[len(filtered)-opts.Limit:]
+	}
+
+	if opts.JSON {
+		enc := json.NewEncoder(p.Out)
+		for _, e := range filtered {
+			if err := enc.Encode(e); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	p.Header("Operation History")
+
+	if len(filtered) == 0 {
+		p.Info("No matching operations")
+		return nil
+	}
+
+	table := ui.NewTable(p.Out, "TIME", "USER", "COMMAND", "RESULT", "DURATION", "AFFECTED")
+	for _, e := range filtered {
+		table.Row(
+			e.Time.Local().Format("2006-01-02 15:04:05"),
+			e.User,
+			strings.Join(append([]string{"flint"}, e.Args...), " "),
+			formatResult(e.Result),
+			(time.Duration(e.DurationMs) * time.Millisecond).Round(100*time.Millisecond).String(),
+			e.Affected(),
+		)
+	}
+	table.Flush()
+
+	return nil
+}
+
+func formatResult(result string) string {
+	switch result {
+	case ResultSuccess:
+		return color.New(color.FgGreen).Sprint(result)
+	case ResultFailed:
+		return color.New(color.FgRed).Sprint(result)
+	default:
+		return color.New(color.FgYellow).Sprint(result)
+	}
+}
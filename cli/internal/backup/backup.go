@@ -8,8 +8,11 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/anibalnet/blackbeard/cli/internal/audit"
+	"github.com/anibalnet/blackbeard/cli/internal/cleanup"
 	"github.com/anibalnet/blackbeard/cli/internal/config"
 	dkr "github.com/anibalnet/blackbeard/cli/internal/docker"
+	"github.com/anibalnet/blackbeard/cli/internal/guard"
 	"github.com/anibalnet/blackbeard/cli/internal/ui"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/filters"
@@ -29,6 +32,7 @@ func ensureAlpine(ctx context.Context, clients *dkr.Clients) error {
 
 func backupVolume(ctx context.Context, clients *dkr.Clients, p *ui.Printer, volumeName, backupPath string) error {
 	p.Info(fmt.Sprintf("Backing up volume: %s", volumeName))
+	audit.AddVolumes(ctx, volumeName)
 
 	absBackupPath, err := filepath.Abs(backupPath)
 	if err != nil {
@@ -70,7 +74,7 @@ func backupVolume(ctx context.Context, clients *dkr.Clients, p *ui.Printer, volu
 	backupFile := filepath.Join(absBackupPath, volumeName+".tar.gz")
 	info, err := os.Stat(backupFile)
 	if err == nil {
-		p.Success(fmt.Sprintf("Backup completed: %s.tar.gz (%s)", volumeName, formatSize(info.Size())))
+		p.Success(fmt.Sprintf("Backup completed: %s.tar.gz (%s)", volumeName, ui.FormatBytes(info.Size())))
 	} else {
 		p.Success(fmt.Sprintf("Backup completed: %s.tar.gz", volumeName))
 	}
@@ -78,19 +82,32 @@ func backupVolume(ctx context.Context, clients *dkr.Clients, p *ui.Printer, volu
 	return nil
 }
 
+// checkSpace refuses to start a backup the backup disk cannot hold. Volume
+// sizes are uncompressed, so the estimate errs on the safe side.
+func checkSpace(ctx context.Context, cfg *config.Config, clients *dkr.Clients, p *ui.Printer, names ...string) error {
+	sizes, err := cleanup.VolumeSizes(ctx, clients)
+	if err != nil {
+		return err
+	}
+	var need int64
+	for _, name := range names {
+		need += sizes[name]
+	}
+	return guard.Require(p, "backup", cfg.BackupDir, need, cfg.DiskReserve)
+}
+
 // RunBackupAll backs up all volumes with the backup.enable=true label.
 func RunBackupAll(ctx context.Context, cfg *config.Config, clients *dkr.Clients, p *ui.Printer) error {
 	timestamp := time.Now().Format("20060102_150405")
 	backupPath := filepath.Join(cfg.BackupDir, timestamp)
 
-	if err := os.MkdirAll(backupPath, 0755); err != nil {
-		return fmt.Errorf("creating backup dir: %w", err)
-	}
-
 	p.Header("Starting Backup Process")
 
 	volumes, err := clients.Engine.VolumeList(ctx, volume.ListOptions{
-		Filters: filters.NewArgs(filters.Arg("label", "backup.enable=true")),
+		Filters: filters.NewArgs(
+			filters.Arg("label", "backup.enable=true"),
+			filters.Arg("label", "com.docker.compose.project="+cfg.ProjectName),
+		),
 	})
 	if err != nil {
 		return fmt.Errorf("listing volumes: %w", err)
@@ -103,6 +120,18 @@ func RunBackupAll(ctx context.Context, cfg *config.Config, clients *dkr.Clients,
 
 	total := len(volumes.Volumes)
 	p.Info(fmt.Sprintf("Found %d volumes to backup", total))
+
+	names := make([]string, 0, total)
+	for _, v := range volumes.Volumes {
+		names = append(names, v.Name)
+	}
+	if err := checkSpace(ctx, cfg, clients, p, names...); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(backupPath, 0755); err != nil {
+		return fmt.Errorf("creating backup dir: %w", err)
+	}
 	p.Info(fmt.Sprintf("Backup destination: %s", backupPath))
 
 	if err := ensureAlpine(ctx, clients); err != nil {
@@ -130,6 +159,9 @@ func RunBackupVolume(ctx context.Context, cfg *config.Config, clients *dkr.Clien
 	timestamp := time.Now().Format("20060102_150405")
 	backupPath := filepath.Join(cfg.BackupDir, timestamp)
 
+	if err := checkSpace(ctx, cfg, clients, p, volumeName); err != nil {
+		return err
+	}
 	if err := os.MkdirAll(backupPath, 0755); err != nil {
 		return fmt.Errorf("creating backup dir: %w", err)
 	}
@@ -140,16 +172,3 @@ func RunBackupVolume(ctx context.Context, cfg *config.Config, clients *dkr.Clien
 
 	return backupVolume(ctx, clients, p, volumeName, backupPath)
 }
-
-func formatSize(bytes int64) string {
-	const unit = 1024
-	if bytes < unit {
-		return fmt.Sprintf("%dB", bytes)
-	}
-	div, exp := int64(unit), 0
-	for n := bytes / unit; n >= unit; n /= unit {
-		div *= unit
-		exp++
-	}
-	return fmt.Sprintf("%.1f%ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
-}
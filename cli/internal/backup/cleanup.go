@@ -7,12 +7,13 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/anibalnet/blackbeard/cli/internal/audit"
 	"github.com/anibalnet/blackbeard/cli/internal/config"
 	"github.com/anibalnet/blackbeard/cli/internal/ui"
 )
 
 // RunCleanup removes backups older than keepDays.
-func RunCleanup(_ context.Context, cfg *config.Config, p *ui.Printer, keepDays int) error {
+func RunCleanup(ctx context.Context, cfg *config.Config, p *ui.Printer, keepDays int) error {
 	p.Header("Cleaning Old Backups")
 	p.Warning(fmt.Sprintf("Removing backups older than %d days", keepDays))
 
@@ -39,6 +40,7 @@ func RunCleanup(_ context.Context, cfg *config.Config, p *ui.Printer, keepDays i
 				p.Error(fmt.Sprintf("removing %s: %s", entry.Name(), err))
 			} else {
 				p.Info(fmt.Sprintf("Removed: %s", entry.Name()))
+				audit.Note(ctx, "removed backup %s", entry.Name())
 				removed++
 			}
 		}
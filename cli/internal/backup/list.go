@@ -5,22 +5,28 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 
 	"github.com/anibalnet/blackbeard/cli/internal/config"
 	"github.com/anibalnet/blackbeard/cli/internal/ui"
 )
 
-// RunListBackups lists available backups on disk.
-func RunListBackups(_ context.Context, cfg *config.Config, p *ui.Printer) error {
-	p.Header("Available Backups")
+// Backup is one timestamped backup run on disk.
+type Backup struct {
+	Name    string `json:"name"`
+	Path    string `json:"path"`
+	Size    int64  `json:"size"`
+	Volumes int    `json:"volumes"`
+}
 
+// ListBackups returns backup runs found in the backup directory, oldest first.
+func ListBackups(cfg *config.Config) ([]Backup, error) {
 	entries, err := os.ReadDir(cfg.BackupDir)
 	if err != nil {
-		p.Warning(fmt.Sprintf("No backups found in %s", cfg.BackupDir))
-		return nil
+		return nil, err
 	}
 
-	found := false
+	var backups []Backup
 	for _, entry := range entries {
 		if !entry.IsDir() {
 			continue
@@ -32,7 +38,6 @@ func RunListBackups(_ context.Context, cfg *config.Config, p *ui.Printer) error
 			continue
 		}
 
-		found = true
 		// Calculate total size
 		var totalSize int64
 		for _, f := range files {
@@ -41,15 +46,34 @@ func RunListBackups(_ context.Context, cfg *config.Config, p *ui.Printer) error
 			}
 		}
 
-		p.Println("")
-		p.Info(fmt.Sprintf("Backup: %s", entry.Name()))
-		p.Println(fmt.Sprintf("  Size: %s", formatSize(totalSize)))
-		p.Println(fmt.Sprintf("  Files: %d volumes", len(files)))
-		p.Println(fmt.Sprintf("  Location: %s", backupDir))
+		backups = append(backups, Backup{
+			Name:    entry.Name(),
+			Path:    backupDir,
+			Size:    totalSize,
+			Volumes: len(files),
+		})
 	}
 
-	if !found {
+	sort.Slice(backups, func(i, j int) bool { return backups[i].Name < backups[j].Name })
+	return backups, nil
+}
+
+// RunListBackups lists available backups on disk.
+func RunListBackups(_ context.Context, cfg *config.Config, p *ui.Printer) error {
+	p.Header("Available Backups")
+
+	backups, err := ListBackups(cfg)
+	if err != nil || len(backups) == 0 {
 		p.Warning(fmt.Sprintf("No backups found in %s", cfg.BackupDir))
+		return nil
+	}
+
+	for _, b := range backups {
+		p.Println("")
+		p.Info(fmt.Sprintf("Backup: %s", b.Name))
+		p.Println(fmt.Sprintf("  Size: %s", ui.FormatBytes(b.Size)))
+		p.Println(fmt.Sprintf("  Files: %d volumes", b.Volumes))
+		p.Println(fmt.Sprintf("  Location: %s", b.Path))
 	}
 
 	return nil
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/anibalnet/blackbeard/cli/internal/audit"
 	"github.com/anibalnet/blackbeard/cli/internal/config"
 	dkr "github.com/anibalnet/blackbeard/cli/internal/docker"
 	"github.com/anibalnet/blackbeard/cli/internal/ui"
@@ -29,10 +30,13 @@ func RunRestore(ctx context.Context, cfg *config.Config, clients *dkr.Clients, p
 	p.Warning(fmt.Sprintf("This will REPLACE all data in volume: %s", volumeName))
 	if !ui.ConfirmYesNo("Are you sure?", skipConfirm) {
 		p.Info("Restore cancelled")
+		audit.Cancelled(ctx)
 		return nil
 	}
 
 	p.Info(fmt.Sprintf("Restoring volume: %s from %s", volumeName, backupFile))
+	audit.AddVolumes(ctx, volumeName)
+	audit.Note(ctx, "restored from %s", backupFile)
 
 	// Ensure volume exists
 	clients.Engine.VolumeCreate(ctx, volume.CreateOptions{Name: volumeName})
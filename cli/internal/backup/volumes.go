@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/anibalnet/blackbeard/cli/internal/config"
 	dkr "github.com/anibalnet/blackbeard/cli/internal/docker"
 	"github.com/anibalnet/blackbeard/cli/internal/ui"
 	"github.com/docker/docker/api/types/filters"
@@ -11,11 +12,14 @@ import (
 )
 
 // RunListVolumes lists volumes marked for backup.
-func RunListVolumes(ctx context.Context, clients *dkr.Clients, p *ui.Printer) error {
+func RunListVolumes(ctx context.Context, cfg *config.Config, clients *dkr.Clients, p *ui.Printer) error {
 	p.Header("Volumes Marked for Backup")
 
 	volumes, err := clients.Engine.VolumeList(ctx, volume.ListOptions{
-		Filters: filters.NewArgs(filters.Arg("label", "backup.enable=true")),
+		Filters: filters.NewArgs(
+			filters.Arg("label", "backup.enable=true"),
+			filters.Arg("label", "com.docker.compose.project="+cfg.ProjectName),
+		),
 	})
 	if err != nil {
 		return fmt.Errorf("listing volumes: %w", err)
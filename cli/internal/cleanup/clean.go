@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/anibalnet/blackbeard/cli/internal/audit"
 	dkr "github.com/anibalnet/blackbeard/cli/internal/docker"
 	"github.com/anibalnet/blackbeard/cli/internal/ui"
 	"github.com/docker/docker/api/types"
@@ -11,47 +12,51 @@ import (
 )
 
 // RunCleanAll performs a complete Docker cleanup.
-func RunCleanAll(ctx context.Context, clients *dkr.Clients, p *ui.Printer, skipConfirm bool) error {
+func RunCleanAll(ctx context.Context, clients *dkr.Clients, p *ui.Printer, skipConfirm, dryRun bool) error {
 	p.Header("Complete Docker Cleanup")
 
-	p.Error("WARNING: This will remove:")
-	p.Println("  - All stopped containers")
-	p.Println("  - All unused networks")
-	p.Println("  - All unused images")
-	p.Println("  - All build cache")
-	p.Println("")
-	p.Warning("Volumes will NOT be removed for safety")
-	p.Println("")
-
-	if !ui.ConfirmTypeFull("Are you ABSOLUTELY sure?", "yes", skipConfirm) {
-		p.Info("Operation cancelled")
+	plan, err := planPrune(ctx, clients, pruneScope{Containers: true, Networks: true, Images: true, BuildCache: true})
+	if err != nil {
+		return err
+	}
+	if !confirmPlan(ctx, p, plan, dryRun, func() bool {
+		p.Error("WARNING: This will remove everything listed above")
+		p.Warning("Volumes will NOT be removed for safety")
+		p.Println("")
+		return ui.ConfirmTypeFull("Are you ABSOLUTELY sure?", "yes", skipConfirm)
+	}) {
 		return nil
 	}
 
 	p.Info("Removing stopped containers...")
-	_, err := clients.Engine.ContainersPrune(ctx, filters.Args{})
+	containersReport, err := clients.Engine.ContainersPrune(ctx, filters.Args{})
 	if err != nil {
 		p.Error(fmt.Sprintf("pruning containers: %s", err))
 	}
+	audit.AddContainers(ctx, containersReport.ContainersDeleted...)
 
 	p.Info("Removing unused networks...")
-	_, err = clients.Engine.NetworksPrune(ctx, filters.Args{})
+	networksReport, err := clients.Engine.NetworksPrune(ctx, filters.Args{})
 	if err != nil {
 		p.Error(fmt.Sprintf("pruning networks: %s", err))
 	}
+	audit.AddNetworks(ctx, networksReport.NetworksDeleted...)
 
 	p.Info("Removing unused images...")
-	_, err = clients.Engine.ImagesPrune(ctx, filters.NewArgs(
+	imagesReport, err := clients.Engine.ImagesPrune(ctx, filters.NewArgs(
 		filters.Arg("dangling", "false"),
 	))
 	if err != nil {
 		p.Error(fmt.Sprintf("pruning images: %s", err))
 	}
+	recordPrunedImages(ctx, imagesReport)
 
 	p.Info("Removing build cache...")
-	_, err = clients.Engine.BuildCachePrune(ctx, types.BuildCachePruneOptions{})
+	cacheReport, err := clients.Engine.BuildCachePrune(ctx, types.BuildCachePruneOptions{})
 	if err != nil {
 		p.Error(fmt.Sprintf("pruning build cache: %s", err))
+	} else if len(cacheReport.CachesDeleted) > 0 {
+		audit.Note(ctx, "removed %d build cache entries", len(cacheReport.CachesDeleted))
 	}
 
 	p.Success("Complete cleanup finished")
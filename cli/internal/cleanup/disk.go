@@ -9,17 +9,63 @@ import (
 	"github.com/docker/docker/api/types"
 )
 
-func formatBytes(b int64) string {
-	const unit = 1024
-	if b < unit {
-		return fmt.Sprintf("%dB", b)
+// DiskTotal is the count and size of one kind of Docker object.
+type DiskTotal struct {
+	Count int   `json:"count"`
+	Size  int64 `json:"size"`
+}
+
+// DiskTotals summarises Docker disk usage per object kind.
+type DiskTotals struct {
+	Images     DiskTotal `json:"images"`
+	Containers DiskTotal `json:"containers"`
+	Volumes    DiskTotal `json:"volumes"`
+	BuildCache DiskTotal `json:"build_cache"`
+}
+
+// DiskUsage returns Docker disk usage totals.
+func DiskUsage(ctx context.Context, clients *dkr.Clients) (*DiskTotals, error) {
+	du, err := clients.Engine.DiskUsage(ctx, types.DiskUsageOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("getting disk usage: %w", err)
+	}
+
+	t := &DiskTotals{}
+	for _, img := range du.Images {
+		t.Images.Count++
+		t.Images.Size += img.Size
+	}
+	for _, c := range du.Containers {
+		t.Containers.Count++
+		t.Containers.Size += c.SizeRw
 	}
-	div, exp := int64(unit), 0
-	for n := b / unit; n >= unit; n /= unit {
-		div *= unit
-		exp++
+	for _, v := range du.Volumes {
+		t.Volumes.Count++
+		if v.UsageData != nil {
+			t.Volumes.Size += v.UsageData.Size
+		}
+	}
+	for _, bc := range du.BuildCache {
+		t.BuildCache.Count++
+		t.BuildCache.Size += bc.Size
+	}
+	return t, nil
+}
+
+// VolumeSizes returns the size of each volume by name. Sizes are only
+// known for local volumes in use by Docker's disk usage accounting.
+func VolumeSizes(ctx context.Context, clients *dkr.Clients) (map[string]int64, error) {
+	du, err := clients.Engine.DiskUsage(ctx, types.DiskUsageOptions{Types: []types.DiskUsageObject{types.VolumeObject}})
+	if err != nil {
+		return nil, fmt.Errorf("getting volume sizes: %w", err)
+	}
+	sizes := make(map[string]int64, len(du.Volumes))
+	for _, v := range du.Volumes {
+		if v.UsageData != nil && v.UsageData.Size > 0 {
+			sizes[v.Name] = v.UsageData.Size
+		}
 	}
-	return fmt.Sprintf("%.1f%ciB", float64(b)/float64(div), "KMGTPE"[exp])
+	return sizes, nil
 }
 
 // RunDisk shows Docker disk usage.
@@ -44,11 +90,11 @@ func RunDisk(ctx context.Context, clients *dkr.Clients, p *ui.Printer) error {
 			repo = parts[0]
 			tag = parts[1]
 		}
-		table.Row(repo, tag, formatBytes(img.Size))
+		table.Row(repo, tag, ui.FormatBytes(img.Size))
 		totalImageSize += img.Size
 	}
 	table.Flush()
-	p.Printf("Total: %s (%d images)\n", formatBytes(totalImageSize), len(du.Images))
+	p.Printf("Total: %s (%d images)\n", ui.FormatBytes(totalImageSize), len(du.Images))
 
 	// Containers
 	p.Println("")
@@ -60,11 +106,11 @@ func RunDisk(ctx context.Context, clients *dkr.Clients, p *ui.Printer) error {
 		if len(c.Names) > 0 {
 			name = c.Names[0][1:]
 		}
-		table.Row(name, c.Image, formatBytes(c.SizeRw))
+		table.Row(name, c.Image, ui.FormatBytes(c.SizeRw))
 		totalContainerSize += c.SizeRw
 	}
 	table.Flush()
-	p.Printf("Total: %s (%d containers)\n", formatBytes(totalContainerSize), len(du.Containers))
+	p.Printf("Total: %s (%d containers)\n", ui.FormatBytes(totalContainerSize), len(du.Containers))
 
 	// Volumes
 	p.Println("")
@@ -72,11 +118,11 @@ func RunDisk(ctx context.Context, clients *dkr.Clients, p *ui.Printer) error {
 	table = ui.NewTable(p.Out, "NAME", "SIZE")
 	var totalVolumeSize int64
 	for _, v := range du.Volumes {
-		table.Row(v.Name, formatBytes(v.UsageData.Size))
+		table.Row(v.Name, ui.FormatBytes(v.UsageData.Size))
 		totalVolumeSize += v.UsageData.Size
 	}
 	table.Flush()
-	p.Printf("Total: %s (%d volumes)\n", formatBytes(totalVolumeSize), len(du.Volumes))
+	p.Printf("Total: %s (%d volumes)\n", ui.FormatBytes(totalVolumeSize), len(du.Volumes))
 
 	// Build Cache
 	p.Println("")
@@ -85,7 +131,7 @@ func RunDisk(ctx context.Context, clients *dkr.Clients, p *ui.Printer) error {
 	for _, bc := range du.BuildCache {
 		totalCacheSize += bc.Size
 	}
-	p.Printf("Total: %s (%d entries)\n", formatBytes(totalCacheSize), len(du.BuildCache))
+	p.Printf("Total: %s (%d entries)\n", ui.FormatBytes(totalCacheSize), len(du.BuildCache))
 
 	return nil
 }
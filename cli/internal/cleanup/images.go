@@ -3,7 +3,9 @@ package cleanup
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"github.com/anibalnet/blackbeard/cli/internal/audit"
 	dkr "github.com/anibalnet/blackbeard/cli/internal/docker"
 	"github.com/anibalnet/blackbeard/cli/internal/ui"
 	"github.com/docker/docker/api/types/container"
@@ -35,7 +37,7 @@ func RunListImages(ctx context.Context, clients *dkr.Clients, p *ui.Printer) err
 			if len(id) > 19 {
 				id = id[:19]
 			}
-			table.Row(repo, tag, id, formatBytes(img.Size))
+			table.Row(repo, tag, id, ui.FormatBytes(img.Size))
 		}
 		table.Flush()
 	}
@@ -61,7 +63,7 @@ func RunListImages(ctx context.Context, clients *dkr.Clients, p *ui.Printer) err
 		if len(id) > 19 {
 			id = id[:19]
 		}
-		table.Row(repo, tag, id, formatBytes(img.Size))
+		table.Row(repo, tag, id, ui.FormatBytes(img.Size))
 	}
 	table.Flush()
 
@@ -84,42 +86,43 @@ func RunListImages(ctx context.Context, clients *dkr.Clients, p *ui.Printer) err
 	return nil
 }
 
-// RunDangling removes dangling images only.
-func RunDangling(ctx context.Context, clients *dkr.Clients, p *ui.Printer) error {
+// RunDangling removes dangling images only. It does not ask for
+// confirmation: untagged, unused images are never needed.
+func RunDangling(ctx context.Context, clients *dkr.Clients, p *ui.Printer, dryRun bool) error {
 	p.Header("Removing Dangling Images")
 
-	dangling, err := clients.Engine.ImageList(ctx, image.ListOptions{
-		Filters: filters.NewArgs(filters.Arg("dangling", "true")),
-	})
+	plan, err := planPrune(ctx, clients, pruneScope{Images: true, DanglingOnly: true})
 	if err != nil {
-		return fmt.Errorf("listing dangling images: %w", err)
+		return err
 	}
-
-	if len(dangling) == 0 {
-		p.Info("No dangling images found")
+	if !confirmPlan(ctx, p, plan, dryRun, func() bool { return true }) {
 		return nil
 	}
 
-	p.Info(fmt.Sprintf("Found %d dangling images", len(dangling)))
-
 	report, err := clients.Engine.ImagesPrune(ctx, filters.NewArgs(
 		filters.Arg("dangling", "true"),
 	))
 	if err != nil {
 		return fmt.Errorf("pruning dangling images: %w", err)
 	}
+	recordPrunedImages(ctx, report)
 
-	p.Success(fmt.Sprintf("Dangling images removed (reclaimed %s)", formatBytes(int64(report.SpaceReclaimed))))
+	p.Success(fmt.Sprintf("Dangling images removed (reclaimed %s)", ui.FormatBytes(int64(report.SpaceReclaimed))))
 	return nil
 }
 
 // RunPruneImages removes all unused images.
-func RunPruneImages(ctx context.Context, clients *dkr.Clients, p *ui.Printer, skipConfirm bool) error {
+func RunPruneImages(ctx context.Context, clients *dkr.Clients, p *ui.Printer, skipConfirm, dryRun bool) error {
 	p.Header("Removing All Unused Images")
 
-	p.Warning("This will remove ALL images not used by containers")
-	if !ui.ConfirmYesNo("Are you sure?", skipConfirm) {
-		p.Info("Operation cancelled")
+	plan, err := planPrune(ctx, clients, pruneScope{Images: true})
+	if err != nil {
+		return err
+	}
+	if !confirmPlan(ctx, p, plan, dryRun, func() bool {
+		p.Warning("This will remove ALL images not used by containers")
+		return ui.ConfirmYesNo("Are you sure?", skipConfirm)
+	}) {
 		return nil
 	}
 
@@ -129,22 +132,28 @@ func RunPruneImages(ctx context.Context, clients *dkr.Clients, p *ui.Printer, sk
 	if err != nil {
 		return fmt.Errorf("pruning images: %w", err)
 	}
+	recordPrunedImages(ctx, report)
 
-	p.Success(fmt.Sprintf("All unused images removed (reclaimed %s)", formatBytes(int64(report.SpaceReclaimed))))
+	p.Success(fmt.Sprintf("All unused images removed (reclaimed %s)", ui.FormatBytes(int64(report.SpaceReclaimed))))
 	return nil
 }
 
 // RunPruneOld removes images older than the given number of days.
-func RunPruneOld(ctx context.Context, clients *dkr.Clients, p *ui.Printer, days int, skipConfirm bool) error {
+func RunPruneOld(ctx context.Context, clients *dkr.Clients, p *ui.Printer, days int, skipConfirm, dryRun bool) error {
 	p.Header(fmt.Sprintf("Removing Images Older Than %d Days", days))
 
-	p.Warning(fmt.Sprintf("This will remove images created more than %d days ago", days))
-	if !ui.ConfirmYesNo("Are you sure?", skipConfirm) {
-		p.Info("Operation cancelled")
+	hours := days * 24
+	plan, err := planPrune(ctx, clients, pruneScope{Images: true, Until: time.Duration(hours) * time.Hour})
+	if err != nil {
+		return err
+	}
+	if !confirmPlan(ctx, p, plan, dryRun, func() bool {
+		p.Warning(fmt.Sprintf("This will remove images created more than %d days ago", days))
+		return ui.ConfirmYesNo("Are you sure?", skipConfirm)
+	}) {
 		return nil
 	}
 
-	hours := days * 24
 	report, err := clients.Engine.ImagesPrune(ctx, filters.NewArgs(
 		filters.Arg("dangling", "false"),
 		filters.Arg("until", fmt.Sprintf("%dh", hours)),
@@ -152,7 +161,20 @@ func RunPruneOld(ctx context.Context, clients *dkr.Clients, p *ui.Printer, days
 	if err != nil {
 		return fmt.Errorf("pruning old images: %w", err)
 	}
+	recordPrunedImages(ctx, report)
 
-	p.Success(fmt.Sprintf("Old images removed (reclaimed %s)", formatBytes(int64(report.SpaceReclaimed))))
+	p.Success(fmt.Sprintf("Old images removed (reclaimed %s)", ui.FormatBytes(int64(report.SpaceReclaimed))))
 	return nil
 }
+
+// recordPrunedImages adds the images removed by a prune to the audit entry,
+// preferring the untagged reference over the bare image ID.
+func recordPrunedImages(ctx context.Context, report image.PruneReport) {
+	for _, deleted := range report.ImagesDeleted {
+		if deleted.Untagged != "" {
+			audit.AddImages(ctx, deleted.Untagged)
+		} else if deleted.Deleted != "" {
+			audit.AddImages(ctx, deleted.Deleted)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package cleanup
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/anibalnet/blackbeard/cli/internal/audit"
+	dkr "github.com/anibalnet/blackbeard/cli/internal/docker"
+	"github.com/anibalnet/blackbeard/cli/internal/ui"
+	"github.com/docker/docker/api/types/container"
+	"github.com/fatih/color"
+)
+
+// ContainerLog is the on-disk log of one container.
+type ContainerLog struct {
+	Name    string
+	Driver  string
+	Path    string
+	Size    int64 // current log file; -1 when it cannot be read
+	Rotated int64 // rotated files kept by the json-file driver
+	MaxSize string
+	MaxFile string
+}
+
+// Bounded reports whether the log driver limits the log's size.
+func (l ContainerLog) Bounded() bool {
+	return l.Driver != "json-file" || l.MaxSize != ""
+}
+
+// limit describes the log size limit, e.g. "10m x 3".
+func (l ContainerLog) limit() string {
+	switch {
+	case l.Driver != "json-file" && l.Driver != "local":
+		return l.Driver
+	case l.MaxSize == "" && l.Driver == "local":
+		return "20m x 5" // the local driver rotates by default
+	case l.MaxSize == "":
+		return color.New(color.FgYellow).Sprint("unbounded")
+	case l.MaxFile == "":
+		return l.MaxSize
+	default:
+		return l.MaxSize + " x " + l.MaxFile
+	}
+}
+
+// LogUsage returns the log files of all containers, largest first. Log
+// files live under the Docker data-root, so sizes are only known on the
+// Docker host and when running as root.
+func LogUsage(ctx context.Context, clients *dkr.Clients) ([]ContainerLog, error) {
+	containers, err := clients.Engine.ContainerList(ctx, container.ListOptions{All: true})
+	if err != nil {
+		return nil, fmt.Errorf("listing containers: %w", err)
+	}
+
+	var logs []ContainerLog
+	for _, c := range containers {
+		inspect, err := clients.Engine.ContainerInspect(ctx, c.ID)
+		if err != nil {
+			continue
+		}
+		l := ContainerLog{Name: strings.TrimPrefix(inspect.Name, "/"), Path: inspect.LogPath, Size: -1}
+		if hc := inspect.HostConfig; hc != nil {
+			l.Driver = hc.LogConfig.Type
+			l.MaxSize = hc.LogConfig.Config["max-size"]
+			l.MaxFile = hc.LogConfig.Config["max-file"]
+		}
+		if l.Path != "" {
+			if info, err := os.Stat(l.Path); err == nil {
+				l.Size = info.Size()
+			}
+			rotated, _ := filepath.Glob(l.Path + ".*")
+			for _, f := range rotated {
+				if info, err := os.Stat(f); err == nil {
+					l.Rotated += info.Size()
+				}
+			}
+		}
+		logs = append(logs, l)
+	}
+	sort.Slice(logs, func(i, j int) bool {
+		if logs[i].Size+logs[i].Rotated != logs[j].Size+logs[j].Rotated {
+			return logs[i].Size+logs[i].Rotated > logs[j].Size+logs[j].Rotated
+		}
+		return logs[i].Name < logs[j].Name
+	})
+	return logs, nil
+}
+
+// RunLogsUsage shows the log size of every container.
+func RunLogsUsage(ctx context.Context, clients *dkr.Clients, p *ui.Printer) error {
+	p.Header("Container Log Usage")
+
+	logs, err := LogUsage(ctx, clients)
+	if err != nil {
+		return err
+	}
+	if len(logs) == 0 {
+		p.Info("No containers found")
+		return nil
+	}
+
+	var total int64
+	unreadable, unbounded := 0, 0
+	table := ui.NewTable(p.Out, "CONTAINER", "DRIVER", "SIZE", "ROTATED", "LIMIT")
+	for _, l := range logs {
+		size := "-"
+		if l.Size >= 0 {
+			size = ui.FormatBytes(l.Size)
+			total += l.Size + l.Rotated
+		} else if l.Path != "" {
+			unreadable++
+		}
+		if !l.Bounded() {
+			unbounded++
+		}
+		table.Row(l.Name, l.Driver, size, ui.FormatBytes(l.Rotated), l.limit())
+	}
+	table.Flush()
+	p.Printf("Total: %s (%d containers)\n", ui.FormatBytes(total), len(logs))
+
+	if unreadable > 0 {
+		p.Println("")
+		p.Warning(fmt.Sprintf("%d log files could not be read; run as root on the Docker host", unreadable))
+	}
+	if unbounded > 0 {
+		p.Println("")
+		p.Info(fmt.Sprintf("%d containers have no log size limit. Set one with: flint docker logs-limit", unbounded))
+		p.Info("Free space now with: flint docker logs-trim")
+	}
+	return nil
+}
+
+// RunLogsTrim truncates the json-file logs of the named containers, or of
+// all containers whose log is at least minSize. The driver appends to the
+// file, so truncating it in place is safe for running containers. With
+// archiveDir the log is first gzipped there, rotating it instead of
+// discarding it.
+func RunLogsTrim(ctx context.Context, clients *dkr.Clients, p *ui.Printer, names []string, minSize int64, archiveDir string, skipConfirm bool) error {
+	p.Header("Trimming Container Logs")
+
+	logs, err := LogUsage(ctx, clients)
+	if err != nil {
+		return err
+	}
+
+	var targets []ContainerLog
+	var total int64
+	for _, l := range logs {
+		if len(names) > 0 && !slices.Contains(names, l.Name) {
+			continue
+		}
+		if l.Driver != "json-file" || l.Size <= 0 || l.Size < minSize {
+			continue
+		}
+		targets = append(targets, l)
+		total += l.Size
+	}
+	if len(targets) == 0 {
+		p.Info("No logs to trim (only readable json-file logs can be trimmed)")
+		return nil
+	}
+
+	table := ui.NewTable(p.Out, "CONTAINER", "SIZE", "LOG FILE")
+	for _, l := range targets {
+		table.Row(l.Name, ui.FormatBytes(l.Size), l.Path)
+	}
+	table.Flush()
+	p.Println("")
+
+	action := "truncate"
+	if archiveDir != "" {
+		action = "archive to " + archiveDir + " and truncate"
+	}
+	if !ui.ConfirmYesNo(fmt.Sprintf("%s %d logs (%s)?", strings.ToUpper(action[:1])+action[1:], len(targets), ui.FormatBytes(total)), skipConfirm) {
+		p.Info("Operation cancelled")
+		audit.Cancelled(ctx)
+		return nil
+	}
+
+	var reclaimed int64
+	failed := 0
+	for _, l := range targets {
+		if archiveDir != "" {
+			if err := archiveLog(l, archiveDir); err != nil {
+				p.Error(fmt.Sprintf("%s: %s", l.Name, err))
+				failed++
+				continue
+			}
+		}
+		if err := os.Truncate(l.Path, 0); err != nil {
+			p.Error(fmt.Sprintf("%s: truncating log: %s", l.Name, err))
+			failed++
+			continue
+		}
+		audit.AddContainers(ctx, l.Name)
+		reclaimed += l.Size
+	}
+
+	p.Success(fmt.Sprintf("Trimmed %d logs (reclaimed %s)", len(targets)-failed, ui.FormatBytes(reclaimed)))
+	if failed > 0 {
+		return fmt.Errorf("%d logs could not be trimmed", failed)
+	}
+	return nil
+}
+
+// archiveLog gzips a container log into dir as <name>-<time>.log.gz.
+func archiveLog(l ContainerLog, dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("creating archive dir: %w", err)
+	}
+	src, err := os.Open(l.Path)
+	if err != nil {
+		return fmt.Errorf("opening log: %w", err)
+	}
+	defer src.Close()
+
+	name := fmt.Sprintf("%s-%s.log.gz", l.Name, time.Now().Format("20060102_150405"))
+	dst, err := os.Create(filepath.Join(dir, name))
+	if err != nil {
+		return fmt.Errorf("creating archive: %w", err)
+	}
+	defer dst.Close()
+
+	zw := gzip.NewWriter(dst)
+	if _, err := io.Copy(zw, src); err != nil {
+		return fmt.Errorf("archiving log: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("archiving log: %w", err)
+	}
+	return dst.Close()
+}
@@ -0,0 +1,227 @@
+package cleanup
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/anibalnet/blackbeard/cli/internal/audit"
+	dkr "github.com/anibalnet/blackbeard/cli/internal/docker"
+	"github.com/anibalnet/blackbeard/cli/internal/ui"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/network"
+)
+
+// Kinds of objects a prune removes.
+const (
+	KindContainer  = "container"
+	KindNetwork    = "network"
+	KindImage      = "image"
+	KindBuildCache = "build cache"
+)
+
+// PruneItem is one object a prune would remove.
+type PruneItem struct {
+	Kind string
+	ID   string
+	Name string
+	Size int64 // space freed by removing it
+}
+
+// PrunePlan lists what a prune would remove. It applies the same rules as
+// the Engine's prune endpoints, so it can be shown before confirming and
+// as a dry run.
+type PrunePlan struct {
+	Items []PruneItem
+}
+
+// Reclaim is the space the prune frees.
+func (pl *PrunePlan) Reclaim() int64 {
+	var total int64
+	for _, item := range pl.Items {
+		total += item.Size
+	}
+	return total
+}
+
+// pruneScope selects what a prune removes.
+type pruneScope struct {
+	Containers   bool          // stopped containers
+	Networks     bool          // custom networks without containers
+	Images       bool          // images no remaining container uses
+	DanglingOnly bool          // only untagged images
+	Until        time.Duration // only images older than this; 0 for all
+	BuildCache   bool          // unused, unshared build cache
+}
+
+// planPrune works out what a prune with scope would remove.
+func planPrune(ctx context.Context, clients *dkr.Clients, scope pruneScope) (*PrunePlan, error) {
+	du, err := clients.Engine.DiskUsage(ctx, types.DiskUsageOptions{
+		Types: []types.DiskUsageObject{types.ContainerObject, types.ImageObject, types.BuildCacheObject},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("getting disk usage: %w", err)
+	}
+
+	plan := &PrunePlan{}
+	usedImages := map[string]bool{}
+	for _, c := range du.Containers {
+		// ContainersPrune removes every container that is not running or paused
+		stopped := c.State == "exited" || c.State == "created" || c.State == "dead"
+		if scope.Containers && stopped {
+			plan.Items = append(plan.Items, PruneItem{Kind: KindContainer, ID: c.ID, Name: containerName(c.Names), Size: c.SizeRw})
+			continue
+		}
+		usedImages[c.ImageID] = true
+	}
+
+	if scope.Networks {
+		networks, err := clients.Engine.NetworkList(ctx, network.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("listing networks: %w", err)
+		}
+		for _, n := range networks {
+			if n.Name == "bridge" || n.Name == "host" || n.Name == "none" || n.Scope == "swarm" {
+				continue
+			}
+			// Endpoints are only reported by inspect, not by list
+			inspect, err := clients.Engine.NetworkInspect(ctx, n.ID, network.InspectOptions{})
+			if err != nil || len(inspect.Containers) > 0 {
+				continue
+			}
+			plan.Items = append(plan.Items, PruneItem{Kind: KindNetwork, ID: n.ID, Name: n.Name})
+		}
+	}
+
+	if scope.Images {
+		cutoff := time.Now().Add(-scope.Until)
+		for _, img := range du.Images {
+			if img == nil || usedImages[img.ID] {
+				continue
+			}
+			if scope.DanglingOnly && !dangling(img) {
+				continue
+			}
+			if scope.Until > 0 && !time.Unix(img.Created, 0).Before(cutoff) {
+				continue
+			}
+			// Layers shared with other images stay on disk
+			size := img.Size
+			if img.SharedSize > 0 {
+				size -= img.SharedSize
+			}
+			plan.Items = append(plan.Items, PruneItem{Kind: KindImage, ID: img.ID, Name: imageName(img), Size: size})
+		}
+	}
+
+	if scope.BuildCache {
+		for _, bc := range du.BuildCache {
+			// Without All, BuildKit keeps records in use, shared with images,
+			// and its internal and frontend records
+			if bc == nil || bc.InUse || bc.Shared || bc.Type == "internal" || bc.Type == "frontend" {
+				continue
+			}
+			plan.Items = append(plan.Items, PruneItem{Kind: KindBuildCache, ID: bc.ID, Name: bc.Description, Size: bc.Size})
+		}
+	}
+	return plan, nil
+}
+
+func dangling(img *image.Summary) bool {
+	for _, tag := range img.RepoTags {
+		if tag != "<none>:<none>" {
+			return false
+		}
+	}
+	return true
+}
+
+func imageName(img *image.Summary) string {
+	if !dangling(img) {
+		return strings.Join(img.RepoTags, ", ")
+	}
+	if len(img.RepoDigests) > 0 {
+		repo, _, _ := strings.Cut(img.RepoDigests[0], "@")
+		return repo + ":<none>"
+	}
+	return "<none>"
+}
+
+func containerName(names []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	return strings.TrimPrefix(names[0], "/")
+}
+
+// shortID trims "sha256:" and shortens an ID like the docker CLI does.
+func shortID(id string) string {
+	id = strings.TrimPrefix(id, "sha256:")
+	if len(id) > 12 {
+		id = id[:12]
+	}
+	return id
+}
+
+// printPlan lists what a prune will remove and the space it frees.
+func printPlan(p *ui.Printer, plan *PrunePlan) {
+	table := ui.NewTable(p.Out, "KIND", "ID", "NAME", "SIZE")
+	for _, item := range plan.Items {
+		name := item.Name
+		if len(name) > 60 {
+			name = name[:57] + "..."
+		}
+		size := "-"
+		if item.Kind != KindNetwork {
+			size = ui.FormatBytes(item.Size)
+		}
+		table.Row(item.Kind, shortID(item.ID), name, size)
+	}
+	table.Flush()
+
+	counts := map[string]int{}
+	for _, item := range plan.Items {
+		counts[item.Kind]++
+	}
+	var parts []string
+	for _, kind := range []string{KindContainer, KindNetwork, KindImage, KindBuildCache} {
+		if n := counts[kind]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, plural(kind, n)))
+		}
+	}
+	p.Printf("Total: %s, reclaiming %s\n", strings.Join(parts, ", "), ui.FormatBytes(plan.Reclaim()))
+}
+
+func plural(kind string, n int) string {
+	switch {
+	case n == 1:
+		return kind
+	case kind == KindBuildCache:
+		return "build cache entries"
+	default:
+		return kind + "s"
+	}
+}
+
+// confirmPlan shows the plan and asks for confirmation. It returns false
+// when there is nothing to do, on a dry run, or when the user declines.
+func confirmPlan(ctx context.Context, p *ui.Printer, plan *PrunePlan, dryRun bool, confirm func() bool) bool {
+	if len(plan.Items) == 0 {
+		p.Info("Nothing to remove")
+		return false
+	}
+	printPlan(p, plan)
+	p.Println("")
+	if dryRun {
+		p.Info("Dry run: nothing was removed")
+		return false
+	}
+	if !confirm() {
+		p.Info("Operation cancelled")
+		audit.Cancelled(ctx)
+		return false
+	}
+	return true
+}
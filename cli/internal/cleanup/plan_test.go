@@ -0,0 +1,153 @@
+package cleanup
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	dkr "github.com/anibalnet/blackbeard/cli/internal/docker"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/network"
+	dockerclient "github.com/docker/docker/client"
+)
+
+// fakeEngine answers the calls planPrune makes; anything else panics on
+// the nil embedded client.
+type fakeEngine struct {
+	dockerclient.APIClient
+	du       types.DiskUsage
+	networks []network.Inspect
+}
+
+func (f *fakeEngine) DiskUsage(context.Context, types.DiskUsageOptions) (types.DiskUsage, error) {
+	return f.du, nil
+}
+
+func (f *fakeEngine) NetworkList(context.Context, network.ListOptions) ([]network.Summary, error) {
+	return f.networks, nil
+}
+
+func (f *fakeEngine) NetworkInspect(_ context.Context, id string, _ network.InspectOptions) (network.Inspect, error) {
+	for _, n := range f.networks {
+		if n.ID == id {
+			return n, nil
+		}
+	}
+	return network.Inspect{}, nil
+}
+
+func testClients() *dkr.Clients {
+	old := time.Now().Add(-30 * 24 * time.Hour).Unix()
+	recent := time.Now().Add(-time.Hour).Unix()
+	return &dkr.Clients{Engine: &fakeEngine{
+		du: types.DiskUsage{
+			Containers: []*types.Container{
+				{ID: "c-run", Names: []string{"/jellyfin"}, ImageID: "i-jellyfin", State: "running"},
+				{ID: "c-exit", Names: []string{"/oneshot"}, ImageID: "i-oneshot", State: "exited", SizeRw: 100},
+				{ID: "c-paused", Names: []string{"/paused"}, ImageID: "i-paused", State: "paused"},
+			},
+			Images: []*image.Summary{
+				{ID: "i-jellyfin", RepoTags: []string{"jellyfin/jellyfin:latest"}, Size: 1000, Created: old},
+				{ID: "i-oneshot", RepoTags: []string{"alpine:3"}, Size: 300, SharedSize: 100, Created: old},
+				{ID: "i-paused", RepoTags: []string{"busybox:1"}, Size: 50, Created: old},
+				{ID: "i-dangling-old", RepoTags: []string{"<none>:<none>"}, RepoDigests: []string{"sonarr@sha256:ab"}, Size: 400, Created: old},
+				{ID: "i-dangling-new", Size: 200, Created: recent},
+				{ID: "i-tagged-new", RepoTags: []string{"radarr:dev"}, Size: 700, Created: recent},
+				nil,
+			},
+			BuildCache: []*types.BuildCache{
+				{ID: "b-free", Description: "RUN make", Size: 10},
+				{ID: "b-inuse", InUse: true, Size: 20},
+				{ID: "b-shared", Shared: true, Size: 30},
+				{ID: "b-internal", Type: "internal", Size: 40},
+				{ID: "b-frontend", Type: "frontend", Size: 50},
+				nil,
+			},
+		},
+		networks: []network.Inspect{
+			{ID: "n-bridge", Name: "bridge"},
+			{ID: "n-host", Name: "host"},
+			{ID: "n-none", Name: "none"},
+			{ID: "n-swarm", Name: "ingress", Scope: "swarm"},
+			{ID: "n-used", Name: "media_default", Containers: map[string]network.EndpointResource{"c-run": {}}},
+			{ID: "n-unused", Name: "old_default"},
+		},
+	}}
+}
+
+func TestPlanPrune(t *testing.T) {
+	tests := []struct {
+		name    string
+		scope   pruneScope
+		ids     []string
+		reclaim int64
+	}{
+		{
+			name:  "everything",
+			scope: pruneScope{Containers: true, Networks: true, Images: true, BuildCache: true},
+			// The exited container goes, so its image is unused too
+			ids:     []string{"c-exit", "n-unused", "i-oneshot", "i-dangling-old", "i-dangling-new", "i-tagged-new", "b-free"},
+			reclaim: 100 + 200 + 400 + 200 + 700 + 10,
+		},
+		{
+			name:    "images only",
+			scope:   pruneScope{Images: true},
+			ids:     []string{"i-dangling-old", "i-dangling-new", "i-tagged-new"},
+			reclaim: 1300,
+		},
+		{
+			name:    "dangling",
+			scope:   pruneScope{Images: true, DanglingOnly: true},
+			ids:     []string{"i-dangling-old", "i-dangling-new"},
+			reclaim: 600,
+		},
+		{
+			name:    "older than a day",
+			scope:   pruneScope{Images: true, Until: 24 * time.Hour},
+			ids:     []string{"i-dangling-old"},
+			reclaim: 400,
+		},
+		{
+			name:    "build cache",
+			scope:   pruneScope{BuildCache: true},
+			ids:     []string{"b-free"},
+			reclaim: 10,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := planPrune(context.Background(), testClients(), tt.scope)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids []string
+			for _, item := range plan.Items {
+				ids = append(ids, item.ID)
+			}
+			if !slices.Equal(ids, tt.ids) {
+				t.Errorf("items = %v, want %v", ids, tt.ids)
+			}
+			if got := plan.Reclaim(); got != tt.reclaim {
+				t.Errorf("Reclaim() = %d, want %d", got, tt.reclaim)
+			}
+		})
+	}
+}
+
+func TestImageName(t *testing.T) {
+	tests := []struct {
+		img  image.Summary
+		want string
+	}{
+		{image.Summary{RepoTags: []string{"a:1", "a:latest"}}, "a:1, a:latest"},
+		{image.Summary{RepoTags: []string{"<none>:<none>"}, RepoDigests: []string{"ghcr.io/x/y@sha256:ab"}}, "ghcr.io/x/y:<none>"},
+		{image.Summary{}, "<none>"},
+	}
+	for _, tt := range tests {
+		if got := imageName(&tt.img); got != tt.want {
+			t.Errorf("imageName(%v) = %q, want %q", tt.img.RepoTags, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// overrideFileNames are picked up next to docker-compose.yml, in the same
+// order docker compose itself looks for them.
+var overrideFileNames = []string{
+	"compose.override.yml",
+	"compose.override.yaml",
+	"docker-compose.override.yml",
+	"docker-compose.override.yaml",
+}
+
+// ResolveComposeFiles sets ComposeFiles from, in priority order: explicit
+// -f flags, COMPOSE_FILE (from the environment or .env), or docker-compose.yml
+// plus the first override file found in the project directory.
+func (c *Config) ResolveComposeFiles(flagFiles []string) error {
+	var files []string
+
+	switch {
+	case len(flagFiles) > 0:
+		for _, f := range flagFiles {
+			abs, err := filepath.Abs(f)
+			if err != nil {
+				return fmt.Errorf("resolving %s: %w", f, err)
+			}
+			files = append(files, abs)
+		}
+	case os.Getenv("COMPOSE_FILE") != "":
+		sep := os.Getenv("COMPOSE_PATH_SEPARATOR")
+		if sep == "" {
+			sep = string(os.PathListSeparator)
+		}
+		for _, f := range strings.Split(os.Getenv("COMPOSE_FILE"), sep) {
+			if f = strings.TrimSpace(f); f == "" {
+				continue
+			}
+			if !filepath.IsAbs(f) {
+				f = filepath.Join(c.ProjectDir, f)
+			}
+			files = append(files, f)
+		}
+	default:
+		files = []string{filepath.Join(c.ProjectDir, "docker-compose.yml")}
+		for _, name := range overrideFileNames {
+			path := filepath.Join(c.ProjectDir, name)
+			if _, err := os.Stat(path); err == nil {
+				files = append(files, path)
+				break
+			}
+		}
+	}
+
+	for _, f := range files {
+		if _, err := os.Stat(f); err != nil {
+			return fmt.Errorf("compose file not found: %s", f)
+		}
+	}
+
+	c.ComposeFiles = files
+	c.explicitFiles = len(flagFiles) > 0 || os.Getenv("COMPOSE_FILE") != ""
+	if len(files) > 0 {
+		c.ComposeFile = files[0]
+	}
+	return nil
+}
+
+// OverrideFile returns the override file flint writes generated settings to:
+// an override already in ComposeFiles, or docker-compose.override.yml, which
+// compose picks up by default. When -f or COMPOSE_FILE select the files
+// compose ignores that default, so without an override among them writing
+// one would have no effect and an error is returned instead.
+func (c *Config) OverrideFile() (string, error) {
+	for _, f := range c.ComposeFiles[min(1, len(c.ComposeFiles)):] {
+		for _, name := range overrideFileNames {
+			if filepath.Base(f) == name {
+				return f, nil
+			}
+		}
+	}
+	path := filepath.Join(c.ProjectDir, "docker-compose.override.yml")
+	if c.explicitFiles {
+		return "", fmt.Errorf("the compose files selected with -f or COMPOSE_FILE include no override file, "+
+			"so compose would ignore %s; create it with \"services: {}\" and add it to the files", path)
+	}
+	return path, nil
+}
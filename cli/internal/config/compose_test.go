@@ -0,0 +1,76 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOverrideFile(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"docker-compose.yml", "extra.yml", "compose.override.yml"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("services: {}\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("COMPOSE_FILE", "")
+
+	tests := []struct {
+		name    string
+		files   []string
+		want    string
+		wantErr bool
+	}{
+		{"default files", nil, "compose.override.yml", false},
+		{"override among -f", []string{"docker-compose.yml", "compose.override.yml"}, "compose.override.yml", false},
+		{"-f without override", []string{"docker-compose.yml", "extra.yml"}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{ProjectDir: dir}
+			var flagFiles []string
+			for _, f := range tt.files {
+				flagFiles = append(flagFiles, filepath.Join(dir, f))
+			}
+			if err := c.ResolveComposeFiles(flagFiles); err != nil {
+				t.Fatal(err)
+			}
+			got, err := c.OverrideFile()
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "docker-compose.override.yml") {
+					t.Errorf("OverrideFile() = %q, %v; want an error naming the file", got, err)
+				}
+				return
+			}
+			if err != nil || got != filepath.Join(dir, tt.want) {
+				t.Errorf("OverrideFile() = %q, %v; want %s", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestOverrideFileDefault(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "docker-compose.yml"), []byte("services: {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("COMPOSE_FILE", "")
+
+	c := &Config{ProjectDir: dir}
+	if err := c.ResolveComposeFiles(nil); err != nil {
+		t.Fatal(err)
+	}
+	// Not there yet, but compose loads it by default once written
+	if got, err := c.OverrideFile(); err != nil || got != filepath.Join(dir, "docker-compose.override.yml") {
+		t.Errorf("OverrideFile() = %q, %v", got, err)
+	}
+
+	t.Setenv("COMPOSE_FILE", "docker-compose.yml")
+	if err := c.ResolveComposeFiles(nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.OverrideFile(); err == nil {
+		t.Error("OverrideFile() with COMPOSE_FILE and no override succeeded")
+	}
+}
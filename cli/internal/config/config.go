@@ -5,18 +5,25 @@ import (
 	"path/filepath"
 	"strconv"
 
+	"github.com/docker/go-units"
 	"github.com/joho/godotenv"
 )
 
+// Fallback names used until the compose project has been loaded, or when it
+// cannot be.
 const (
-	NetworkName = "jollyroger"
-	ProjectName = "media-stack"
+	DefaultNetworkName = "jollyroger"
+	DefaultProjectName = "media-stack"
 )
 
 // Config holds all configuration derived from .env and environment.
 type Config struct {
 	ProjectDir     string
-	ComposeFile    string
+	Instance       string // --project name; empty for the default instance
+	ProjectName    string
+	ComposeFile    string   // primary compose file (first of ComposeFiles)
+	ComposeFiles   []string // all compose files merged in order
+	explicitFiles  bool     // ComposeFiles came from -f or COMPOSE_FILE
 	EnvFile        string
 	EnvExample     string
 	BackupDir      string
@@ -28,11 +35,54 @@ type Config struct {
 	ConfigBasePath string
 	GPUVideoGroup  string
 	GPURenderGroup string
+	StateDir       string
+	AuditLog       string
+	MetricsDir     string
+
+	// RemoteBin and RemoteProjectDir are used when host-level commands are
+	// forwarded over SSH to a remote board.
+	RemoteBin        string
+	RemoteProjectDir string
+
+	// Inventory is the fleet file of named hosts and groups used by --hosts.
+	Inventory string
+
+	// SensorsFile holds user labels and groups for temperature sensors.
+	SensorsFile string
+
+	// APIToken is the bearer token required by the flint serve REST API.
+	// The API is disabled when it is empty.
+	APIToken string
+
+	// DiskReserve is the free space, in bytes, backups and updates must
+	// leave on the disk they write to (FLINT_DISK_RESERVE, e.g. "5GB").
+	DiskReserve int64
+
+	// qBittorrent Web API used by the serve storage guard to pause downloads
+	// when the downloads disk fills up.
+	QbitURL        string
+	QbitUser       string
+	QbitPassword   string
+	GuardPausePct  int // pause at or above this use% of the downloads disk
+	GuardResumePct int // resume at or below this use%
+
+	// DisabledServices are the compose services moved to the disabled
+	// profile with flint stack disable. Set when the project is loaded.
+	DisabledServices []string
+
+	// PortOffset is added to every published host port so a --project
+	// instance can run beside the default stack (FLINT_PORT_OFFSET).
+	PortOffset int
 }
 
-// Load reads the .env file and environment to populate Config.
-func Load(projectDir string) (*Config, error) {
+// Load reads the .env file and environment to populate Config. A non-empty
+// instance selects .env.<instance> and uses it as the compose project name,
+// so several stacks can share one checkout.
+func Load(projectDir, instance string) (*Config, error) {
 	envFile := filepath.Join(projectDir, ".env")
+	if instance != "" {
+		envFile += "." + instance
+	}
 	envExample := filepath.Join(projectDir, ".env.example")
 
 	// Load .env if it exists (ignore error if not present)
@@ -40,10 +90,13 @@ func Load(projectDir string) (*Config, error) {
 
 	cfg := &Config{
 		ProjectDir:     projectDir,
+		Instance:       instance,
+		ProjectName:    DefaultProjectName,
 		ComposeFile:    filepath.Join(projectDir, "docker-compose.yml"),
+		ComposeFiles:   []string{filepath.Join(projectDir, "docker-compose.yml")},
 		EnvFile:        envFile,
 		EnvExample:     envExample,
-		NetworkName:    NetworkName,
+		NetworkName:    DefaultNetworkName,
 		PUID:           getEnvInt("PUID", os.Getuid()),
 		PGID:           getEnvInt("PGID", os.Getgid()),
 		TZ:             getEnv("TZ", "America/Sao_Paulo"),
@@ -53,7 +106,26 @@ func Load(projectDir string) (*Config, error) {
 		GPURenderGroup: getEnv("GPU_RENDER_GROUP", "105"),
 	}
 
+	if instance != "" {
+		cfg.ProjectName = instance
+	}
+
 	cfg.BackupDir = getEnv("BACKUP_DIR", filepath.Join(projectDir, "backups"))
+	cfg.StateDir = getEnv("FLINT_STATE_DIR", filepath.Join(projectDir, ".flint"))
+	cfg.AuditLog = getEnv("FLINT_AUDIT_LOG", filepath.Join(cfg.StateDir, "audit.jsonl"))
+	cfg.MetricsDir = getEnv("FLINT_METRICS_DIR", filepath.Join(cfg.StateDir, "metrics"))
+	cfg.PortOffset = getEnvInt("FLINT_PORT_OFFSET", 0)
+	cfg.RemoteBin = getEnv("FLINT_REMOTE_BIN", "flint")
+	cfg.RemoteProjectDir = os.Getenv("FLINT_REMOTE_DIR")
+	cfg.Inventory = getEnv("FLINT_INVENTORY", userConfigFile("inventory.yml"))
+	cfg.SensorsFile = getEnv("FLINT_SENSORS", userConfigFile("sensors.yml"))
+	cfg.APIToken = os.Getenv("FLINT_API_TOKEN")
+	cfg.DiskReserve = getEnvSize("FLINT_DISK_RESERVE", 2<<30)
+	cfg.QbitURL = getEnv("FLINT_QBIT_URL", "http://localhost:5080")
+	cfg.QbitUser = os.Getenv("FLINT_QBIT_USER")
+	cfg.QbitPassword = os.Getenv("FLINT_QBIT_PASSWORD")
+	cfg.GuardPausePct = getEnvInt("FLINT_GUARD_PAUSE_PCT", 95)
+	cfg.GuardResumePct = getEnvInt("FLINT_GUARD_RESUME_PCT", 90)
 
 	return cfg, nil
 }
@@ -86,3 +158,22 @@ func getEnvInt(key string, fallback int) int {
 	}
 	return fallback
 }
+
+// getEnvSize parses a human size such as "5GB" or "512m" (binary units).
+func getEnvSize(key string, fallback int64) int64 {
+	if v := os.Getenv(key); v != "" {
+		if n, err := units.RAMInBytes(v); err == nil && n >= 0 {
+			return n
+		}
+	}
+	return fallback
+}
+
+// userConfigFile returns ~/.config/flint/<name>.
+func userConfigFile(name string) string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return name
+	}
+	return filepath.Join(dir, "flint", name)
+}
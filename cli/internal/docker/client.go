@@ -1,8 +1,11 @@
 package docker
 
 import (
+	"strings"
+
 	"github.com/docker/cli/cli/command"
 	"github.com/docker/cli/cli/flags"
+	registryclient "github.com/docker/cli/cli/registry/client"
 	"github.com/docker/compose/v2/pkg/api"
 	"github.com/docker/compose/v2/pkg/compose"
 	dockerclient "github.com/docker/docker/client"
@@ -15,14 +18,63 @@ type Clients struct {
 	cli     *command.DockerCli
 }
 
-// NewClients creates Docker Engine client + Compose service.
-func NewClients() (*Clients, error) {
+// Endpoint selects the Docker daemon to talk to. Empty fields fall back to
+// DOCKER_HOST, DOCKER_CONTEXT and the current docker context.
+type Endpoint struct {
+	Host    string // e.g. ssh://pi@blackbeard or tcp://10.0.0.5:2376
+	Context string
+}
+
+// IsLocalHost reports whether a daemon address is a socket on this machine,
+// such as a rootless DOCKER_HOST, rather than another host.
+func IsLocalHost(host string) bool {
+	return host == "" || strings.HasPrefix(host, "unix://") || strings.HasPrefix(host, "npipe://")
+}
+
+// Local reports whether the daemon runs on this machine, so paths it reports
+// can be inspected directly.
+func (c *Clients) Local() bool {
+	return IsLocalHost(c.cli.DockerEndpoint().Host)
+}
+
+// Registry returns a client for image registries that signs in with the
+// credentials of the docker CLI configuration.
+func (c *Clients) Registry() registryclient.RegistryClient {
+	return c.cli.RegistryClient(false)
+}
+
+func newDockerCli(ep Endpoint) (*command.DockerCli, error) {
 	dockerCli, err := command.NewDockerCli()
 	if err != nil {
 		return nil, err
 	}
 
-	if err := dockerCli.Initialize(flags.NewClientOptions()); err != nil {
+	opts := flags.NewClientOptions()
+	if ep.Host != "" {
+		opts.Hosts = []string{ep.Host}
+	}
+	opts.Context = ep.Context
+
+	if err := dockerCli.Initialize(opts); err != nil {
+		return nil, err
+	}
+	return dockerCli, nil
+}
+
+// ResolveHost returns the daemon address the endpoint points at, resolving
+// docker contexts, without connecting to it.
+func ResolveHost(ep Endpoint) (string, error) {
+	dockerCli, err := newDockerCli(ep)
+	if err != nil {
+		return "", err
+	}
+	return dockerCli.DockerEndpoint().Host, nil
+}
+
+// NewClients creates Docker Engine client + Compose service.
+func NewClients(ep Endpoint) (*Clients, error) {
+	dockerCli, err := newDockerCli(ep)
+	if err != nil {
 		return nil, err
 	}
 
@@ -2,21 +2,34 @@ package docker
 
 import (
 	"context"
+	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/compose-spec/compose-go/v2/cli"
 	"github.com/compose-spec/compose-go/v2/types"
 )
 
-// LoadProject loads a compose project from the given compose file
-// with the given .env file for variable interpolation.
-func LoadProject(ctx context.Context, composeFile string, envFile string) (*types.Project, error) {
-	options, err := cli.NewProjectOptions(
-		[]string{composeFile},
+// LoadProject loads a compose project by merging the given compose files in
+// order, with the given .env file for variable interpolation. A non-empty
+// name overrides the project name declared in the compose files.
+func LoadProject(ctx context.Context, composeFiles []string, envFile, name string) (*types.Project, error) {
+	var envFiles []string
+	if _, err := os.Stat(envFile); err == nil {
+		envFiles = []string{envFile}
+	}
+
+	opts := []cli.ProjectOptionsFn{
 		cli.WithOsEnv,
+		cli.WithEnvFiles(envFiles...),
 		cli.WithDotEnv,
-	)
+	}
+	if name != "" {
+		opts = append(opts, cli.WithName(name))
+	}
+
+	options, err := cli.NewProjectOptions(composeFiles, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -29,16 +42,51 @@ func LoadProject(ctx context.Context, composeFile string, envFile string) (*type
 	return project, nil
 }
 
+// ExternalNetwork returns the name of the external network the project's
+// services attach to, or "" when there is none.
+func ExternalNetwork(project *types.Project) string {
+	var names []string
+	for key, n := range project.Networks {
+		if !bool(n.External) {
+			continue
+		}
+		for _, svc := range project.Services {
+			if _, ok := svc.Networks[key]; ok {
+				names = append(names, n.Name)
+				break
+			}
+		}
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	sort.Strings(names)
+	return names[0]
+}
+
 // ConfigDirsFromProject extracts bind-mount device paths from the project's
 // volume definitions and returns them relative to configBasePath.
-// Only volumes whose resolved device path falls under configBasePath are included.
+// Only volumes mounted by an active service and whose resolved device path
+// falls under configBasePath are included.
 func ConfigDirsFromProject(project *types.Project, configBasePath string) []string {
 	absBase, _ := filepath.Abs(configBasePath)
 
+	used := map[string]bool{}
+	for _, svc := range project.Services {
+		for _, mount := range svc.Volumes {
+			if mount.Type == types.VolumeTypeVolume {
+				used[mount.Source] = true
+			}
+		}
+	}
+
 	var dirs []string
 	seen := map[string]bool{}
 
-	for _, vol := range project.Volumes {
+	for name, vol := range project.Volumes {
+		if !used[name] {
+			continue
+		}
 		device, ok := vol.DriverOpts["device"]
 		if !ok {
 			continue
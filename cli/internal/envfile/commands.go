@@ -0,0 +1,143 @@
+package envfile
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/anibalnet/blackbeard/cli/internal/audit"
+	"github.com/anibalnet/blackbeard/cli/internal/config"
+	"github.com/anibalnet/blackbeard/cli/internal/ui"
+)
+
+func loadEnv(cfg *config.Config) (*File, error) {
+	f, err := Load(cfg.EnvFile)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf(".env not found at %s (run: flint stack install)", cfg.EnvFile)
+	}
+	return f, err
+}
+
+// RunGet prints the value of a single variable from .env.
+func RunGet(_ context.Context, cfg *config.Config, p *ui.Printer, key string) error {
+	f, err := loadEnv(cfg)
+	if err != nil {
+		return err
+	}
+	value, ok := f.Get(key)
+	if !ok {
+		return fmt.Errorf("%s is not set in .env", key)
+	}
+	p.Println(value)
+	return nil
+}
+
+// RunSet sets a variable in .env, adding it when missing.
+func RunSet(ctx context.Context, cfg *config.Config, p *ui.Printer, key, value string) error {
+	f, err := loadEnv(cfg)
+	if err != nil {
+		return err
+	}
+
+	old, existed := f.Get(key)
+	if existed && old == value {
+		p.Info(fmt.Sprintf("%s is already %q", key, value))
+		return nil
+	}
+
+	if err := f.Set(key, value); err != nil {
+		return err
+	}
+	if err := f.Save(cfg.EnvFile); err != nil {
+		return fmt.Errorf("writing .env: %w", err)
+	}
+
+	// Values may be secrets such as API tokens, so only the key is recorded
+	// and the previous value is never echoed
+	audit.Note(ctx, ".env set %s", key)
+	if existed {
+		p.Success(fmt.Sprintf("Updated %s", key))
+	} else {
+		p.Success(fmt.Sprintf("Added %s", key))
+	}
+	return nil
+}
+
+// RunUnset removes a variable from .env.
+func RunUnset(ctx context.Context, cfg *config.Config, p *ui.Printer, key string) error {
+	f, err := loadEnv(cfg)
+	if err != nil {
+		return err
+	}
+
+	if !f.Unset(key) {
+		p.Info(fmt.Sprintf("%s is not set in .env", key))
+		return nil
+	}
+	if err := f.Save(cfg.EnvFile); err != nil {
+		return fmt.Errorf("writing .env: %w", err)
+	}
+
+	audit.Note(ctx, ".env unset %s", key)
+	p.Success(fmt.Sprintf("Removed %s", key))
+	return nil
+}
+
+// RunDiff shows keys missing from .env or not present in .env.example.
+func RunDiff(_ context.Context, cfg *config.Config, p *ui.Printer) error {
+	p.Header(".env vs .env.example")
+
+	env, err := loadEnv(cfg)
+	if err != nil {
+		return err
+	}
+	example, err := Load(cfg.EnvExample)
+	if err != nil {
+		return fmt.Errorf("reading .env.example: %w", err)
+	}
+
+	envValues := env.Map()
+	exampleValues := example.Map()
+
+	var missing, extra []string
+	for _, key := range example.Keys() {
+		if _, ok := envValues[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	for _, key := range env.Keys() {
+		if _, ok := exampleValues[key]; !ok {
+			extra = append(extra, key)
+		}
+	}
+
+	if len(missing) == 0 && len(extra) == 0 {
+		p.Success(fmt.Sprintf(".env defines the same %d keys as .env.example", len(exampleValues)))
+		return nil
+	}
+
+	if len(missing) > 0 {
+		p.Warning(fmt.Sprintf("Missing from .env (%d):", len(missing)))
+		table := ui.NewTable(p.Out, "  KEY", "EXAMPLE VALUE")
+		for _, key := range missing {
+			table.Row("  "+key, exampleValues[key])
+		}
+		table.Flush()
+		p.Println("")
+	}
+
+	if len(extra) > 0 {
+		p.Info(fmt.Sprintf("Not in .env.example (%d):", len(extra)))
+		table := ui.NewTable(p.Out, "  KEY", "VALUE")
+		for _, key := range extra {
+			table.Row("  "+key, envValues[key])
+		}
+		table.Flush()
+		p.Println("")
+	}
+
+	if len(missing) > 0 {
+		p.Info("Add missing keys with: flint env set KEY VALUE")
+	}
+	return nil
+}
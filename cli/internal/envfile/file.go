@@ -0,0 +1,308 @@
+package envfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// File is a parsed dotenv file that remembers every line, so comments,
+// blank lines, ordering and quoting survive an edit round-trip.
+type File struct {
+	lines []line
+	eol   string // line ending for appended lines, taken from the first line
+}
+
+type line struct {
+	raw     string // original text; used verbatim for comments and untouched entries
+	key     string // empty for comments and blank lines
+	value   string // unquoted, unescaped value
+	export  bool   // line started with "export "
+	quote   byte   // 0, '"' or '\''
+	comment string // trailing inline comment including the leading '#'
+	dirty   bool   // value changed since parsing
+	eol     string // "\n" or "\r\n" as read
+}
+
+// Load reads and parses a dotenv file.
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(string(data)), nil
+}
+
+// Parse parses dotenv content. Lines that are not KEY=VALUE assignments are
+// kept as-is and never interpreted. Each line keeps its LF or CRLF ending.
+func Parse(content string) *File {
+	f := &File{eol: "\n"}
+	content = strings.TrimSuffix(content, "\n")
+	if content == "" {
+		return f
+	}
+	for i, raw := range strings.Split(content, "\n") {
+		l := parseLine(strings.TrimSuffix(raw, "\r"))
+		l.eol = "\n"
+		if strings.HasSuffix(raw, "\r") {
+			l.eol = "\r\n"
+		}
+		if i == 0 {
+			f.eol = l.eol
+		}
+		f.lines = append(f.lines, l)
+	}
+	return f
+}
+
+func parseLine(raw string) line {
+	l := line{raw: raw}
+
+	s := strings.TrimSpace(raw)
+	if s == "" || strings.HasPrefix(s, "#") {
+		return l
+	}
+
+	if rest, ok := strings.CutPrefix(s, "export "); ok {
+		l.export = true
+		s = strings.TrimSpace(rest)
+	}
+
+	key, rest, ok := strings.Cut(s, "=")
+	key = strings.TrimSpace(key)
+	if !ok || !validKey(key) {
+		return line{raw: raw}
+	}
+	l.key = key
+
+	rest = strings.TrimLeft(rest, " \t")
+	switch {
+	case strings.HasPrefix(rest, `"`):
+		l.quote = '"'
+		value, tail := scanDoubleQuoted(rest[1:])
+		l.value = value
+		l.comment = trailingComment(tail)
+	case strings.HasPrefix(rest, "'"):
+		l.quote = '\''
+		end := strings.IndexByte(rest[1:], '\'')
+		if end < 0 {
+			l.value = rest[1:]
+		} else {
+			l.value = rest[1 : end+1]
+			l.comment = trailingComment(rest[end+2:])
+		}
+	default:
+		if idx := strings.Index(rest, " #"); idx >= 0 {
+			l.comment = strings.TrimSpace(rest[idx:])
+			rest = rest[:idx]
+		} else if strings.HasPrefix(rest, "#") {
+			l.comment = rest
+			rest = ""
+		}
+		l.value = strings.TrimSpace(rest)
+	}
+
+	return l
+}
+
+// scanDoubleQuoted returns the unescaped value up to the closing quote and
+// whatever follows it.
+func scanDoubleQuoted(s string) (value, tail string) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s):
+			i++
+			switch s[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			default:
+				b.WriteByte(s[i])
+			}
+		case c == '"':
+			return b.String(), s[i+1:]
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String(), ""
+}
+
+func trailingComment(tail string) string {
+	tail = strings.TrimSpace(tail)
+	if strings.HasPrefix(tail, "#") {
+		return tail
+	}
+	return ""
+}
+
+func validKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for i, r := range key {
+		switch {
+		case r == '_', r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z':
+		case r >= '0' && r <= '9' && i > 0:
+		case r == '.' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Get returns the value of key. When a key is assigned more than once the
+// last assignment wins, matching how docker compose reads the file.
+func (f *File) Get(key string) (string, bool) {
+	for i := len(f.lines) - 1; i >= 0; i-- {
+		if f.lines[i].key == key {
+			return f.lines[i].value, true
+		}
+	}
+	return "", false
+}
+
+// Set updates key in place, keeping its export prefix, quoting and inline
+// comment, or appends a new assignment when the key is missing.
+func (f *File) Set(key, value string) error {
+	if !validKey(key) {
+		return fmt.Errorf("invalid variable name %q", key)
+	}
+	for i := len(f.lines) - 1; i >= 0; i-- {
+		if f.lines[i].key == key {
+			if f.lines[i].value != value {
+				f.lines[i].value = value
+				f.lines[i].dirty = true
+			}
+			return nil
+		}
+	}
+	f.lines = append(f.lines, line{key: key, value: value, dirty: true, eol: f.eol})
+	return nil
+}
+
+// Unset removes every assignment of key. Returns false if it was not present.
+func (f *File) Unset(key string) bool {
+	kept := f.lines[:0]
+	removed := false
+	for _, l := range f.lines {
+		if l.key == key {
+			removed = true
+			continue
+		}
+		kept = append(kept, l)
+	}
+	f.lines = kept
+	return removed
+}
+
+// Keys returns assigned keys in file order, without duplicates.
+func (f *File) Keys() []string {
+	var keys []string
+	seen := map[string]bool{}
+	for _, l := range f.lines {
+		if l.key != "" && !seen[l.key] {
+			seen[l.key] = true
+			keys = append(keys, l.key)
+		}
+	}
+	return keys
+}
+
+// Map returns all assignments as a map.
+func (f *File) Map() map[string]string {
+	m := make(map[string]string)
+	for _, l := range f.lines {
+		if l.key != "" {
+			m[l.key] = l.value
+		}
+	}
+	return m
+}
+
+// String renders the file. Untouched lines are reproduced byte for byte.
+func (f *File) String() string {
+	var b strings.Builder
+	for _, l := range f.lines {
+		if l.key == "" || !l.dirty {
+			b.WriteString(l.raw)
+		} else {
+			b.WriteString(l.render())
+		}
+		b.WriteString(l.eol)
+	}
+	return b.String()
+}
+
+func (l line) render() string {
+	var b strings.Builder
+	if l.export {
+		b.WriteString("export ")
+	}
+	b.WriteString(l.key)
+	b.WriteByte('=')
+
+	quote := l.quote
+	if quote == 0 && needsQuoting(l.value) {
+		quote = '"'
+	}
+	if quote == '\'' && strings.ContainsRune(l.value, '\'') {
+		quote = '"'
+	}
+
+	switch quote {
+	case '"':
+		b.WriteByte('"')
+		r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`)
+		b.WriteString(r.Replace(l.value))
+		b.WriteByte('"')
+	case '\'':
+		b.WriteByte('\'')
+		b.WriteString(l.value)
+		b.WriteByte('\'')
+	default:
+		b.WriteString(l.value)
+	}
+
+	if l.comment != "" {
+		b.WriteByte(' ')
+		b.WriteString(l.comment)
+	}
+	return b.String()
+}
+
+func needsQuoting(value string) bool {
+	return strings.ContainsAny(value, " \t\n#\"'\\$`")
+}
+
+// Save writes the file atomically, keeping the mode of any existing file.
+func (f *File) Save(path string) error {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".env.tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.WriteString(f.String()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,173 @@
+package envfile
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestParseValues(t *testing.T) {
+	f := Parse(`# media stack
+export TZ=Europe/Madrid
+PUID = 1000
+MEDIA_DIR="/srv/media files" # library root
+ESCAPED="a\"b\nc"
+SINGLE='it''s' # trailing
+PLAIN=value # note
+EMPTY=
+HASH=#not-a-value
+not an assignment
+1BAD=x
+`)
+
+	tests := []struct {
+		key, want string
+	}{
+		{"TZ", "Europe/Madrid"},
+		{"PUID", "1000"},
+		{"MEDIA_DIR", "/srv/media files"},
+		{"ESCAPED", "a\"b\nc"},
+		{"SINGLE", "it"},
+		{"PLAIN", "value"},
+		{"EMPTY", ""},
+		{"HASH", ""},
+	}
+	for _, tt := range tests {
+		got, ok := f.Get(tt.key)
+		if !ok || got != tt.want {
+			t.Errorf("Get(%q) = %q, %v; want %q", tt.key, got, ok, tt.want)
+		}
+	}
+
+	if _, ok := f.Get("1BAD"); ok {
+		t.Error("Get(1BAD) found a key that is not a valid name")
+	}
+	want := []string{"TZ", "PUID", "MEDIA_DIR", "ESCAPED", "SINGLE", "PLAIN", "EMPTY", "HASH"}
+	if got := f.Keys(); !slices.Equal(got, want) {
+		t.Errorf("Keys() = %v, want %v", got, want)
+	}
+}
+
+func TestLastAssignmentWins(t *testing.T) {
+	f := Parse("PORT=1\nPORT=2\n")
+	if got, _ := f.Get("PORT"); got != "2" {
+		t.Errorf("Get(PORT) = %q, want 2", got)
+	}
+	if err := f.Set("PORT", "3"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := f.String(), "PORT=1\nPORT=3\n"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestRoundTripUnchanged(t *testing.T) {
+	content := "# comment\r\n\nexport A = 'x' # keep\nB=\"y\"\n  garbage line\n"
+	if got := Parse(content).String(); got != content {
+		t.Errorf("String() = %q, want %q", got, content)
+	}
+}
+
+func TestSetKeepsCRLF(t *testing.T) {
+	f := Parse("# c\r\nA=\"x\" # note\r\nB=2\r\n")
+	if got, _ := f.Get("A"); got != "x" {
+		t.Errorf("Get(A) = %q, want x", got)
+	}
+	if err := f.Set("A", "y"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Set("NEW", "v"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := f.String(), "# c\r\nA=\"y\" # note\r\nB=2\r\nNEW=v\r\n"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestSetKeepsFormatting(t *testing.T) {
+	tests := []struct {
+		name, content, key, value, want string
+	}{
+		{"plain", "A=1 # port\n", "A", "2", "A=2 # port\n"},
+		{"export", "export A=1\n", "A", "2", "export A=2\n"},
+		{"double quoted", "A=\"x\"\n", "A", "a\"b", "A=\"a\\\"b\"\n"},
+		{"single quoted", "A='x'\n", "A", "y z", "A='y z'\n"},
+		{"single quote in value", "A='x'\n", "A", "it's", "A=\"it's\"\n"},
+		{"needs quoting", "A=x\n", "A", "has space", "A=\"has space\"\n"},
+		{"unchanged value", "A = 1\n", "A", "1", "A = 1\n"},
+		{"appended", "# top\n", "NEW", "v", "# top\nNEW=v\n"},
+		{"appended to empty", "", "NEW", "a\tb", "NEW=\"a\\tb\"\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := Parse(tt.content)
+			if err := f.Set(tt.key, tt.value); err != nil {
+				t.Fatal(err)
+			}
+			if got := f.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+			if got, _ := Parse(f.String()).Get(tt.key); got != tt.value {
+				t.Errorf("re-parsed value = %q, want %q", got, tt.value)
+			}
+		})
+	}
+}
+
+func TestSetInvalidKey(t *testing.T) {
+	for _, key := range []string{"", "1A", "A-B", "A B"} {
+		if err := Parse("").Set(key, "x"); err == nil {
+			t.Errorf("Set(%q) succeeded, want an error", key)
+		}
+	}
+}
+
+func TestUnset(t *testing.T) {
+	f := Parse("# c\nA=1\nB=2\nA=3\n")
+	if !f.Unset("A") {
+		t.Fatal("Unset(A) = false, want true")
+	}
+	if f.Unset("A") {
+		t.Error("second Unset(A) = true, want false")
+	}
+	if got, want := f.String(), "# c\nB=2\n"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestSaveKeepsMode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte("A=1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	f, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Set("A", "2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "A=2\n" {
+		t.Errorf("saved %q, want %q", data, "A=2\n")
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+	}
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("directory has %d entries, want only .env", len(entries))
+	}
+}
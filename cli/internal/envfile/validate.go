@@ -0,0 +1,201 @@
+package envfile
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/anibalnet/blackbeard/cli/internal/config"
+	"github.com/anibalnet/blackbeard/cli/internal/ui"
+	"github.com/docker/go-units"
+	"github.com/fatih/color"
+)
+
+// varRef matches ${NAME}, ${NAME:-default}, ${NAME-default}, ${NAME:?err} and $NAME.
+var varRef = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(?:(:?[-?+])([^}]*))?\}|\$([A-Za-z_][A-Za-z0-9_]*)`)
+
+// Reference is a variable used by the compose file together with its
+// effective value.
+type Reference struct {
+	Name       string
+	Default    string
+	HasDefault bool
+	Value      string
+	Source     string // ".env", "environment", "default" or "unset"
+}
+
+// Problem is a validation failure for a single variable.
+type Problem struct {
+	Name    string
+	Message string
+}
+
+// ComposeReferences returns every variable interpolated in the given compose
+// files, resolved against .env and the process environment.
+func ComposeReferences(composeFiles []string, env *File) ([]Reference, error) {
+	refs := map[string]*Reference{}
+
+	for _, file := range composeFiles {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			if strings.HasPrefix(strings.TrimSpace(line), "#") {
+				continue
+			}
+			// "$$" is an escaped dollar sign in compose files.
+			line = strings.ReplaceAll(line, "$$", "")
+			for _, m := range varRef.FindAllStringSubmatch(line, -1) {
+				name := m[1]
+				if name == "" {
+					name = m[4]
+				}
+				ref, ok := refs[name]
+				if !ok {
+					ref = &Reference{Name: name}
+					refs[name] = ref
+				}
+				if strings.HasSuffix(m[2], "-") && !ref.HasDefault {
+					ref.Default = m[3]
+					ref.HasDefault = true
+				}
+			}
+		}
+	}
+
+	var envValues map[string]string
+	if env != nil {
+		envValues = env.Map()
+	}
+
+	result := make([]Reference, 0, len(refs))
+	for _, ref := range refs {
+		fileValue, inFile := envValues[ref.Name]
+		osValue, inOS := os.LookupEnv(ref.Name)
+		switch {
+		case inOS && (!inFile || osValue != fileValue):
+			ref.Value, ref.Source = osValue, "environment"
+		case inFile:
+			ref.Value, ref.Source = fileValue, ".env"
+		case ref.HasDefault:
+			ref.Value, ref.Source = ref.Default, "default"
+		default:
+			ref.Source = "unset"
+		}
+		result = append(result, *ref)
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+// CheckReference validates a single variable by naming convention.
+// Returns an empty string when the value is acceptable.
+func CheckReference(ref Reference, projectDir string) string {
+	if ref.Source == "unset" {
+		return "not set and has no default"
+	}
+	value := ref.Value
+	name := ref.Name
+
+	switch {
+	case strings.HasSuffix(name, "_PATH") || name == "BACKUP_DIR":
+		if value == "" {
+			return "empty path"
+		}
+		path := value
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(projectDir, path)
+		}
+		if _, err := os.Stat(path); err != nil {
+			return fmt.Sprintf("path does not exist: %s", path)
+		}
+	case name == "PUID" || name == "PGID" || strings.HasSuffix(name, "_GROUP"):
+		if n, err := strconv.Atoi(value); err != nil || n < 0 {
+			return fmt.Sprintf("must be a numeric ID, got %q", value)
+		}
+	case name == "UMASK":
+		if _, err := strconv.ParseUint(value, 8, 32); err != nil {
+			return fmt.Sprintf("must be an octal mask, got %q", value)
+		}
+	case strings.HasSuffix(name, "_MEM_LIMIT"):
+		if _, err := units.RAMInBytes(value); err != nil {
+			return fmt.Sprintf("not a Docker memory size (e.g. 512m, 2g): %q", value)
+		}
+	case strings.HasSuffix(name, "_CPU_LIMIT"):
+		if n, err := strconv.ParseFloat(value, 64); err != nil || n <= 0 {
+			return fmt.Sprintf("must be a positive number of CPUs (e.g. 0.5, 2.0), got %q", value)
+		}
+	case strings.HasSuffix(name, "_PORT"):
+		if n, err := strconv.Atoi(value); err != nil || n < 1 || n > 65535 {
+			return fmt.Sprintf("must be a port number between 1 and 65535, got %q", value)
+		}
+	case name == "TZ":
+		if _, err := time.LoadLocation(value); err != nil {
+			return fmt.Sprintf("unknown timezone %q", value)
+		}
+	}
+	return ""
+}
+
+// Validate checks every variable referenced by the compose files.
+func Validate(cfg *config.Config) ([]Reference, []Problem, error) {
+	env, err := Load(cfg.EnvFile)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, nil, fmt.Errorf("reading .env: %w", err)
+	}
+
+	refs, err := ComposeReferences(cfg.ComposeFiles, env)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading compose file: %w", err)
+	}
+
+	var problems []Problem
+	for _, ref := range refs {
+		if msg := CheckReference(ref, cfg.ProjectDir); msg != "" {
+			problems = append(problems, Problem{Name: ref.Name, Message: msg})
+		}
+	}
+	return refs, problems, nil
+}
+
+// RunValidate validates every variable referenced in the compose files.
+func RunValidate(_ context.Context, cfg *config.Config, p *ui.Printer) error {
+	p.Header("Environment Validation")
+
+	refs, problems, err := Validate(cfg)
+	if err != nil {
+		return err
+	}
+
+	failed := map[string]string{}
+	for _, prob := range problems {
+		failed[prob.Name] = prob.Message
+	}
+
+	okText := color.New(color.FgGreen).Sprint("OK")
+	table := ui.NewTable(p.Out, "VARIABLE", "VALUE", "SOURCE", "STATUS")
+	for _, ref := range refs {
+		status := okText
+		if msg, bad := failed[ref.Name]; bad {
+			status = color.New(color.FgRed).Sprint(msg)
+		}
+		table.Row(ref.Name, ref.Value, ref.Source, status)
+	}
+	table.Flush()
+	p.Println("")
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%d of %d variable(s) invalid", len(problems), len(refs))
+	}
+
+	p.Success(fmt.Sprintf("All %d variables referenced by the compose files are valid", len(refs)))
+	return nil
+}
@@ -0,0 +1,103 @@
+package fleet
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Host is a single Blackbeard board in the inventory.
+type Host struct {
+	Name    string `yaml:"-"`
+	Host    string `yaml:"host"`    // Docker endpoint, e.g. ssh://pi@grandma.lan
+	Context string `yaml:"context"` // Docker context, instead of host
+	Project string `yaml:"project"` // instance name (.env.<project>) for this board
+}
+
+// Inventory lists named hosts and groups of them.
+//
+//	hosts:
+//	  home:    {host: ssh://pi@blackbeard}
+//	  grandma: {host: ssh://pi@grandma.lan, project: grandma}
+//	groups:
+//	  family: [grandma]
+type Inventory struct {
+	Hosts  map[string]*Host    `yaml:"hosts"`
+	Groups map[string][]string `yaml:"groups"`
+}
+
+// LoadInventory reads and validates an inventory file.
+func LoadInventory(path string) (*Inventory, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("inventory not found at %s (set FLINT_INVENTORY)", path)
+		}
+		return nil, err
+	}
+
+	var inv Inventory
+	if err := yaml.Unmarshal(data, &inv); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	for name, h := range inv.Hosts {
+		if h == nil || (h.Host == "" && h.Context == "") {
+			return nil, fmt.Errorf("inventory host %q needs a host or context", name)
+		}
+		h.Name = name
+	}
+	for group, members := range inv.Groups {
+		if _, clash := inv.Hosts[group]; clash {
+			return nil, fmt.Errorf("inventory group %q has the same name as a host", group)
+		}
+		for _, m := range members {
+			if _, ok := inv.Hosts[m]; !ok {
+				return nil, fmt.Errorf("inventory group %q references unknown host %q", group, m)
+			}
+		}
+	}
+	return &inv, nil
+}
+
+// Select resolves a --hosts selector: "all", or a comma separated list of
+// host and group names. Hosts are returned once each, sorted by name.
+func (inv *Inventory) Select(selector string) ([]*Host, error) {
+	picked := map[string]bool{}
+	for _, name := range strings.Split(selector, ",") {
+		name = strings.TrimSpace(name)
+		switch {
+		case name == "":
+		case name == "all":
+			for n := range inv.Hosts {
+				picked[n] = true
+			}
+		case inv.Groups[name] != nil:
+			for _, n := range inv.Groups[name] {
+				picked[n] = true
+			}
+		case inv.Hosts[name] != nil:
+			picked[name] = true
+		default:
+			return nil, fmt.Errorf("no host or group named %q in inventory", name)
+		}
+	}
+	if len(picked) == 0 {
+		return nil, fmt.Errorf("--hosts %q selects no hosts", selector)
+	}
+
+	names := make([]string, 0, len(picked))
+	for n := range picked {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	hosts := make([]*Host, len(names))
+	for i, n := range names {
+		hosts[i] = inv.Hosts[n]
+	}
+	return hosts, nil
+}
@@ -0,0 +1,217 @@
+package fleet
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/anibalnet/blackbeard/cli/internal/config"
+	"github.com/anibalnet/blackbeard/cli/internal/ui"
+	"github.com/fatih/color"
+)
+
+// Result is the outcome of running one command on one host.
+type Result struct {
+	Host     *Host
+	Output   []byte
+	ExitCode int
+	Err      error
+	Duration time.Duration
+}
+
+// cellSep splits aligned table output back into cells.
+var cellSep = regexp.MustCompile(`\s{2,}`)
+
+// ChildArgs strips --hosts from a flint command line so it can be re-run
+// once per host.
+func ChildArgs(args []string) []string {
+	var out []string
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		if a == "--" {
+			out = append(out, args[i:]...)
+			break
+		}
+		if a == "--hosts" {
+			i++
+			continue
+		}
+		if strings.HasPrefix(a, "--hosts=") {
+			continue
+		}
+		out = append(out, a)
+	}
+	return out
+}
+
+// Run executes flint once per host concurrently, targeting each host with
+// --host/--context (and --project when the inventory sets one).
+func Run(ctx context.Context, hosts []*Host, args []string) []Result {
+	self, err := os.Executable()
+	if err != nil {
+		self = os.Args[0]
+	}
+
+	results := make([]Result, len(hosts))
+	var wg sync.WaitGroup
+	for i, h := range hosts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = runHost(ctx, self, h, args)
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+func runHost(ctx context.Context, self string, h *Host, args []string) Result {
+	var hostArgs []string
+	if h.Host != "" {
+		hostArgs = append(hostArgs, "--host", h.Host)
+	} else {
+		hostArgs = append(hostArgs, "--context", h.Context)
+	}
+	if h.Project != "" {
+		hostArgs = append(hostArgs, "--project", h.Project)
+	}
+
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, self, append(hostArgs, args...)...)
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	cmd.Env = childEnv(os.Environ())
+
+	start := time.Now()
+	err := cmd.Run()
+	res := Result{Host: h, Output: out.Bytes(), Duration: time.Since(start)}
+
+	var exitErr *exec.ExitError
+	switch {
+	case errors.As(err, &exitErr):
+		res.ExitCode = exitErr.ExitCode()
+	case err != nil:
+		res.ExitCode = 1
+		res.Err = err
+	}
+	return res
+}
+
+// childEnv returns the environment for a per-host run. The host selection
+// and daemon variables are dropped: each child gets its target as flags,
+// and FLINT_HOSTS would make it reject them or fan out again.
+func childEnv(environ []string) []string {
+	env := make([]string, 0, len(environ)+1)
+	for _, kv := range environ {
+		name, _, _ := strings.Cut(kv, "=")
+		switch name {
+		case "FLINT_HOSTS", "DOCKER_HOST", "DOCKER_CONTEXT":
+			continue
+		}
+		env = append(env, kv)
+	}
+	// Force plain output so tables can be re-aligned with a host column
+	return append(env, "NO_COLOR=1")
+}
+
+// PrintAggregated prints every host's output as one table with a leading
+// HOST column. Section headers and repeated table headers are shown once.
+func PrintAggregated(p *ui.Printer, results []Result) {
+	w := tabwriter.NewWriter(p.Out, 0, 0, 2, ' ', 0)
+	seenHeaders := map[string]bool{}
+
+	for _, r := range results {
+		sc := bufio.NewScanner(bytes.NewReader(r.Output))
+		for sc.Scan() {
+			line := strings.TrimRight(sc.Text(), " ")
+			trimmed := strings.TrimSpace(line)
+			if trimmed == "" || strings.ContainsAny(trimmed[:min(len(trimmed), 3)], "╔║╚") {
+				continue
+			}
+
+			cells := cellSep.Split(trimmed, -1)
+			if len(cells) > 1 && isHeaderRow(cells) {
+				if !seenHeaders[trimmed] {
+					seenHeaders[trimmed] = true
+					fmt.Fprintln(w, "HOST\t"+strings.Join(cells, "\t"))
+				}
+				continue
+			}
+			fmt.Fprintln(w, r.Host.Name+"\t"+strings.Join(cells, "\t"))
+		}
+		if r.Err != nil {
+			fmt.Fprintf(w, "%s\t✗ %s\n", r.Host.Name, r.Err)
+		}
+	}
+	w.Flush()
+}
+
+// headerCell matches a column title as printed by ui.NewTable.
+var headerCell = regexp.MustCompile(`^[A-Z][A-Z _%/()-]*$`)
+
+// isHeaderRow reports whether every cell looks like a column title.
+func isHeaderRow(cells []string) bool {
+	for _, c := range cells {
+		if !headerCell.MatchString(c) {
+			return false
+		}
+	}
+	return true
+}
+
+// PrintSummary prints one line per host with its exit status and returns
+// the number of hosts that failed.
+func PrintSummary(p *ui.Printer, results []Result) int {
+	p.Println("")
+	p.Info("Per-host summary:")
+
+	failed := 0
+	table := ui.NewTable(p.Out, "HOST", "TARGET", "RESULT", "EXIT", "DURATION")
+	for _, r := range results {
+		target := r.Host.Host
+		if target == "" {
+			target = "context " + r.Host.Context
+		}
+		result := color.New(color.FgGreen).Sprint("ok")
+		if r.ExitCode != 0 || r.Err != nil {
+			failed++
+			result = color.New(color.FgRed).Sprint("failed")
+		}
+		table.Row(r.Host.Name, target, result, fmt.Sprint(r.ExitCode), r.Duration.Round(100*time.Millisecond).String())
+	}
+	table.Flush()
+	return failed
+}
+
+// RunFleet runs the current command on every selected inventory host and
+// prints the combined output followed by a per-host summary.
+func RunFleet(ctx context.Context, cfg *config.Config, p *ui.Printer, selector string, args []string) error {
+	inv, err := LoadInventory(cfg.Inventory)
+	if err != nil {
+		return err
+	}
+	hosts, err := inv.Select(selector)
+	if err != nil {
+		return err
+	}
+
+	p.Info(fmt.Sprintf("Running on %d host(s)...", len(hosts)))
+	p.Println("")
+
+	results := Run(ctx, hosts, ChildArgs(args))
+	PrintAggregated(p, results)
+
+	if failed := PrintSummary(p, results); failed > 0 {
+		return fmt.Errorf("%d of %d host(s) failed", failed, len(hosts))
+	}
+	return nil
+}
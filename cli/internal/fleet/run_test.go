@@ -0,0 +1,57 @@
+package fleet
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestChildArgs(t *testing.T) {
+	tests := []struct {
+		in, want []string
+	}{
+		{[]string{"--hosts", "all", "stack", "status"}, []string{"stack", "status"}},
+		{[]string{"--hosts=pi,nas", "stack", "status"}, []string{"stack", "status"}},
+		{[]string{"stack", "logs", "--", "--hosts", "x"}, []string{"stack", "logs", "--", "--hosts", "x"}},
+	}
+	for _, tt := range tests {
+		if got := ChildArgs(tt.in); !slices.Equal(got, tt.want) {
+			t.Errorf("ChildArgs(%v) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+// TestRunHostFromEnvSelection runs a stand-in for flint the way a fleet
+// run selected with FLINT_HOSTS does, and checks the child only sees its
+// own target.
+func TestRunHostFromEnvSelection(t *testing.T) {
+	t.Setenv("FLINT_HOSTS", "all")
+	t.Setenv("DOCKER_HOST", "unix:///var/run/docker.sock")
+	t.Setenv("DOCKER_CONTEXT", "laptop")
+
+	self := filepath.Join(t.TempDir(), "flint")
+	script := "#!/bin/sh\necho \"args=$*\"\necho \"hosts=$FLINT_HOSTS docker=$DOCKER_HOST$DOCKER_CONTEXT color=$NO_COLOR\"\n"
+	if err := os.WriteFile(self, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	h := &Host{Name: "pi", Host: "ssh://pi@blackbeard", Project: "kids"}
+	res := runHost(context.Background(), self, h, ChildArgs([]string{"stack", "status"}))
+	if res.Err != nil || res.ExitCode != 0 {
+		t.Fatalf("runHost failed: %v (exit %d): %s", res.Err, res.ExitCode, res.Output)
+	}
+	want := "args=--host ssh://pi@blackbeard --project kids stack status\nhosts= docker= color=1\n"
+	if got := string(res.Output); got != want {
+		t.Errorf("child saw %q, want %q", got, want)
+	}
+}
+
+func TestChildEnv(t *testing.T) {
+	env := childEnv([]string{"HOME=/root", "FLINT_HOSTS=all", "DOCKER_HOST=ssh://x", "DOCKER_CONTEXT=y", "FLINT_HOSTS_FILE=z"})
+	if got := strings.Join(env, " "); got != "HOME=/root FLINT_HOSTS_FILE=z NO_COLOR=1" {
+		t.Errorf("childEnv = %q", got)
+	}
+}
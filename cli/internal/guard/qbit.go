@@ -0,0 +1,142 @@
+package guard
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Qbit is a minimal qBittorrent Web API client.
+type Qbit struct {
+	base       string
+	user, pass string
+	http       *http.Client
+}
+
+// NewQbit returns a client for the Web UI at baseURL. Without a user the
+// client relies on qBittorrent's "bypass authentication for localhost".
+func NewQbit(baseURL, user, pass string) *Qbit {
+	jar, _ := cookiejar.New(nil)
+	return &Qbit{
+		base: strings.TrimSuffix(baseURL, "/"),
+		user: user,
+		pass: pass,
+		http: &http.Client{Jar: jar, Timeout: 10 * time.Second},
+	}
+}
+
+func (q *Qbit) login(ctx context.Context) error {
+	if q.user == "" {
+		return nil
+	}
+	body, status, err := q.do(ctx, http.MethodPost, "/api/v2/auth/login",
+		url.Values{"username": {q.user}, "password": {q.pass}})
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK || strings.TrimSpace(body) != "Ok." {
+		return fmt.Errorf("qBittorrent login failed: check FLINT_QBIT_USER and FLINT_QBIT_PASSWORD")
+	}
+	return nil
+}
+
+// call sends a request, logging in again once when the session has expired.
+func (q *Qbit) call(ctx context.Context, method, path string, form url.Values) (string, int, error) {
+	body, status, err := q.do(ctx, method, path, form)
+	if err == nil && status == http.StatusForbidden && q.user != "" {
+		if err := q.login(ctx); err != nil {
+			return "", 0, err
+		}
+		body, status, err = q.do(ctx, method, path, form)
+	}
+	return body, status, err
+}
+
+func (q *Qbit) do(ctx context.Context, method, path string, form url.Values) (string, int, error) {
+	var body io.Reader
+	if form != nil {
+		body = strings.NewReader(form.Encode())
+	}
+	req, err := http.NewRequestWithContext(ctx, method, q.base+path, body)
+	if err != nil {
+		return "", 0, err
+	}
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	// qBittorrent's CSRF check compares Referer with the Web UI host
+	req.Header.Set("Referer", q.base)
+
+	resp, err := q.http.Do(req)
+	if err != nil {
+		return "", 0, fmt.Errorf("contacting qBittorrent: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 8<<20))
+	if err != nil {
+		return "", 0, fmt.Errorf("reading qBittorrent response: %w", err)
+	}
+	return string(data), resp.StatusCode, nil
+}
+
+// Active returns the hashes of torrents that are not stopped.
+func (q *Qbit) Active(ctx context.Context) ([]string, error) {
+	body, status, err := q.call(ctx, http.MethodGet, "/api/v2/torrents/info", nil)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("listing torrents: qBittorrent returned %d", status)
+	}
+	var torrents []struct {
+		Hash  string `json:"hash"`
+		State string `json:"state"`
+	}
+	if err := json.Unmarshal([]byte(body), &torrents); err != nil {
+		return nil, fmt.Errorf("parsing torrent list: %w", err)
+	}
+	var hashes []string
+	for _, t := range torrents {
+		// "stopped*" since qBittorrent 5, "paused*" before
+		if strings.HasPrefix(t.State, "stopped") || strings.HasPrefix(t.State, "paused") {
+			continue
+		}
+		hashes = append(hashes, t.Hash)
+	}
+	return hashes, nil
+}
+
+// Stop stops the given torrents.
+func (q *Qbit) Stop(ctx context.Context, hashes []string) error {
+	return q.torrents(ctx, "stop", "pause", hashes)
+}
+
+// Start starts the given torrents again.
+func (q *Qbit) Start(ctx context.Context, hashes []string) error {
+	return q.torrents(ctx, "start", "resume", hashes)
+}
+
+// torrents calls a torrent action, falling back to its pre-5.0 name.
+func (q *Qbit) torrents(ctx context.Context, action, legacy string, hashes []string) error {
+	if len(hashes) == 0 {
+		return nil
+	}
+	form := url.Values{"hashes": {strings.Join(hashes, "|")}}
+	_, status, err := q.call(ctx, http.MethodPost, "/api/v2/torrents/"+action, form)
+	if err == nil && status == http.StatusNotFound {
+		_, status, err = q.call(ctx, http.MethodPost, "/api/v2/torrents/"+legacy, form)
+	}
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("%s torrents: qBittorrent returned %d", action, status)
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+// Package guard keeps flint and the stack from filling up disks: pre-flight
+// free-space checks for operations that write a lot, and a watcher that
+// pauses qBittorrent while the downloads disk is nearly full.
+package guard
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/anibalnet/blackbeard/cli/internal/hw"
+	"github.com/anibalnet/blackbeard/cli/internal/ui"
+)
+
+// ErrLowSpace is returned when an operation would eat into the disk reserve.
+var ErrLowSpace = errors.New("not enough free disk space")
+
+// Require checks that the filesystem holding path can take need more bytes
+// and still keep reserve free. what names the operation in messages; need
+// is 0 when the operation's size is not known.
+func Require(p *ui.Printer, what, path string, need, reserve int64) error {
+	free, err := hw.FreeSpace(path)
+	if err != nil {
+		return err
+	}
+	estimate := ""
+	if need > 0 {
+		estimate = fmt.Sprintf("%s needs about %s, ", what, ui.FormatBytes(need))
+	}
+	if int64(free)-need < reserve {
+		return fmt.Errorf("%w on %s for %s: %s%s is free and %s must stay in reserve (FLINT_DISK_RESERVE)",
+			ErrLowSpace, path, what, estimate, ui.FormatBytes(int64(free)), ui.FormatBytes(reserve))
+	}
+	p.Info(fmt.Sprintf("Free space on %s: %s (%s%s reserved)", path, ui.FormatBytes(int64(free)), estimate, ui.FormatBytes(reserve)))
+	return nil
+}
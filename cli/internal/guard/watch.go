@@ -0,0 +1,131 @@
+package guard
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/anibalnet/blackbeard/cli/internal/config"
+	"github.com/anibalnet/blackbeard/cli/internal/hw"
+)
+
+// WatchInterval is how often the watcher checks the downloads disk.
+const WatchInterval = time.Minute
+
+// Watcher pauses qBittorrent while the downloads disk is nearly full and
+// resumes the same torrents once space returns.
+type Watcher struct {
+	path      string
+	pausePct  float64
+	resumePct float64
+	qbit      *Qbit
+	stateFile string
+}
+
+// watchState is persisted so torrents paused before a restart of the daemon
+// are still resumed afterwards.
+type watchState struct {
+	Paused []string  `json:"paused"`
+	Since  time.Time `json:"since"`
+}
+
+// NewWatcher creates a watcher for the configured downloads path.
+func NewWatcher(cfg *config.Config) *Watcher {
+	return &Watcher{
+		path:      cfg.DownloadsPath,
+		pausePct:  float64(cfg.GuardPausePct),
+		resumePct: float64(cfg.GuardResumePct),
+		qbit:      NewQbit(cfg.QbitURL, cfg.QbitUser, cfg.QbitPassword),
+		stateFile: filepath.Join(cfg.StateDir, "storage-guard.json"),
+	}
+}
+
+// Check looks at the downloads disk once, pausing or resuming torrents as
+// needed. It returns a message when it acted.
+func (w *Watcher) Check(ctx context.Context) (string, error) {
+	pct, err := hw.UsedPercent(w.path)
+	if err != nil {
+		return "", err
+	}
+	state, err := w.load()
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case state == nil && pct >= w.pausePct:
+		hashes, err := w.qbit.Active(ctx)
+		if err != nil {
+			return "", err
+		}
+		if err := w.qbit.Stop(ctx, hashes); err != nil {
+			return "", err
+		}
+		if err := w.save(&watchState{Paused: hashes, Since: time.Now()}); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Downloads disk at %.1f%%: paused %d torrents", pct, len(hashes)), nil
+
+	case state != nil && pct <= w.resumePct:
+		if err := w.qbit.Start(ctx, state.Paused); err != nil {
+			return "", err
+		}
+		if err := os.Remove(w.stateFile); err != nil {
+			return "", fmt.Errorf("clearing storage guard state: %w", err)
+		}
+		return fmt.Sprintf("Downloads disk at %.1f%%: resumed %d torrents", pct, len(state.Paused)), nil
+	}
+	return "", nil
+}
+
+// Run checks the downloads disk every WatchInterval until ctx is cancelled.
+func (w *Watcher) Run(ctx context.Context, onEvent func(string), onErr func(error)) {
+	ticker := time.NewTicker(WatchInterval)
+	defer ticker.Stop()
+	for {
+		if msg, err := w.Check(ctx); err != nil {
+			onErr(err)
+		} else if msg != "" {
+			onEvent(msg)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *Watcher) load() (*watchState, error) {
+	data, err := os.ReadFile(w.stateFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading storage guard state: %w", err)
+	}
+	var state watchState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", w.stateFile, err)
+	}
+	return &state, nil
+}
+
+func (w *Watcher) save(state *watchState) error {
+	if err := os.MkdirAll(filepath.Dir(w.stateFile), 0755); err != nil {
+		return fmt.Errorf("creating state dir: %w", err)
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(w.stateFile, data, 0644); err != nil {
+		return fmt.Errorf("writing storage guard state: %w", err)
+	}
+	return nil
+}
@@ -2,12 +2,49 @@ package hw
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/anibalnet/blackbeard/cli/internal/ui"
+	"github.com/fatih/color"
 	"github.com/shirou/gopsutil/v4/cpu"
 )
 
+// CPUReading is overall and per-core CPU usage over a sampling interval.
+type CPUReading struct {
+	Model   string    `json:"model"`
+	Cores   int       `json:"cores"`
+	Percent float64   `json:"percent"`
+	PerCore []float64 `json:"per_core"`
+	Freqs   []int64   `json:"freqs"` // current frequency per core in Hz
+}
+
+// ReadCPU samples CPU usage over interval.
+func ReadCPU(interval time.Duration) (CPUReading, error) {
+	var r CPUReading
+	if infos, err := cpu.Info(); err == nil && len(infos) > 0 {
+		r.Model = infos[0].ModelName
+		r.Cores = len(infos)
+	}
+
+	perCore, err := cpu.Percent(interval, true)
+	if err != nil {
+		return r, fmt.Errorf("reading CPU usage: %w", err)
+	}
+	r.PerCore = perCore
+	r.Freqs = ReadCoreFreqs(len(perCore))
+
+	var total float64
+	for _, pct := range perCore {
+		total += pct
+	}
+	if len(perCore) > 0 {
+		r.Percent = total / float64(len(perCore))
+	}
+	return r, nil
+}
+
 // RunCPU shows CPU model, core count, and per-core usage.
 func RunCPU(p *ui.Printer) error {
 	p.Header("CPU")
@@ -32,11 +69,97 @@ func RunCPU(p *ui.Printer) error {
 	p.Printf("Usage:  %.1f%%\n", overall)
 
 	p.Println("")
-	table := ui.NewTable(p.Out, "CORE", "USAGE")
+	freqs := ReadCoreFreqs(len(perCorePcts))
+	table := ui.NewTable(p.Out, "CORE", "USAGE", "FREQ")
 	for i, pct := range perCorePcts {
-		table.Row(fmt.Sprintf("core-%d", i), fmt.Sprintf("%.1f%%", pct))
+		table.Row(fmt.Sprintf("core-%d", i), fmt.Sprintf("%.1f%%", pct), formatMHz(freqs[i]))
 	}
 	table.Flush()
 
+	throttle := ReadThrottle()
+	if len(throttle.Policies) > 0 {
+		p.Println("")
+		p.Println("Frequency policies:")
+		table = ui.NewTable(p.Out, "POLICY", "CPUS", "GOVERNOR", "CUR", "MIN", "MAX", "HW MAX")
+		for _, pol := range throttle.Policies {
+			maxFreq := formatMHz(pol.MaxFreq)
+			if pol.Capped() {
+				maxFreq = color.New(color.FgYellow).Sprint(maxFreq)
+			}
+			table.Row(pol.Name, formatCPUList(pol.CPUs), pol.Governor,
+				formatMHz(pol.CurFreq), formatMHz(pol.MinFreq), maxFreq, formatMHz(pol.HWMaxFreq))
+		}
+		table.Flush()
+	}
+
+	p.Println("")
+	printThrottle(p, throttle)
 	return nil
 }
+
+// printThrottle shows the throttling verdict, its reasons and the thermal
+// zones with their trip points.
+func printThrottle(p *ui.Printer, t Throttle) {
+	p.Printf("Throttling: %s\n", FormatThrottle(t))
+	for _, r := range t.Reasons {
+		p.Printf("  - %s\n", r)
+	}
+	if len(t.Zones) == 0 {
+		return
+	}
+
+	p.Println("")
+	table := ui.NewTable(p.Out, "ZONE", "TYPE", "TEMP", "TRIPS")
+	for _, z := range t.Zones {
+		trips := make([]string, 0, len(z.Trips))
+		for _, trip := range z.Trips {
+			trips = append(trips, fmt.Sprintf("%s %.0f°C", trip.Type, trip.Celsius))
+		}
+		table.Row(z.Name, z.Type, FormatTemp(TempReading{Celsius: z.Celsius, Valid: true}), strings.Join(trips, ", "))
+	}
+	table.Flush()
+
+	var limiters, fans []string
+	for _, c := range t.Cooling {
+		if c.MaxState == 0 {
+			continue
+		}
+		state := fmt.Sprintf("%s %d/%d", c.Type, c.CurState, c.MaxState)
+		if c.Limiter() {
+			limiters = append(limiters, state)
+		} else {
+			fans = append(fans, state)
+		}
+	}
+	if len(limiters) > 0 {
+		p.Printf("Cooling devices: %s\n", strings.Join(limiters, ", "))
+	}
+	if len(fans) > 0 {
+		p.Printf("Fans: %s\n", strings.Join(fans, ", "))
+	}
+}
+
+func formatMHz(hz int64) string {
+	if hz <= 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d MHz", hz/1000000)
+}
+
+// formatCPUList prints CPU numbers as ranges, e.g. "0-3".
+func formatCPUList(cpus []int) string {
+	var parts []string
+	for i := 0; i < len(cpus); {
+		j := i
+		for j+1 < len(cpus) && cpus[j+1] == cpus[j]+1 {
+			j++
+		}
+		if i == j {
+			parts = append(parts, strconv.Itoa(cpus[i]))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d-%d", cpus[i], cpus[j]))
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ",")
+}
@@ -1,41 +1,231 @@
 package hw
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/anibalnet/blackbeard/cli/internal/ui"
 	"github.com/fatih/color"
 	"github.com/shirou/gopsutil/v4/disk"
 )
 
-// DefaultDiskPaths are checked when no args are given.
-var DefaultDiskPaths = []string{"/", "/media/STORAGE"}
+// Storage roles of the stack's own directories.
+const (
+	RoleDownloads  = "downloads"
+	RoleConfig     = "config"
+	RoleBackups    = "backups"
+	RoleDockerRoot = "docker"
+)
 
-// RunDisk shows disk usage for the given paths (or defaults).
-func RunDisk(p *ui.Printer, paths []string) error {
-	p.Header("Disk Usage")
+// StoragePath is a directory the stack stores data in.
+type StoragePath struct {
+	Role string
+	Path string
+}
+
+// MountUsage is a mounted filesystem and the stack paths on it.
+type MountUsage struct {
+	Mountpoint string
+	Device     string
+	Fstype     string
+	Total      uint64
+	Used       uint64
+	Free       uint64
+	UsedPct    float64
+	Roles      []string
+}
+
+// existingPath resolves path, or its nearest existing parent when it does
+// not exist yet, following symlinks.
+func existingPath(path string) string {
+	for {
+		if resolved, err := filepath.EvalSymlinks(path); err == nil {
+			return resolved
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return path
+		}
+		path = parent
+	}
+}
+
+// FreeSpace returns the space available to unprivileged users on the
+// filesystem that holds, or will hold, path.
+func FreeSpace(path string) (uint64, error) {
+	usage, err := disk.Usage(existingPath(path))
+	if err != nil {
+		return 0, fmt.Errorf("checking free space of %s: %w", path, err)
+	}
+	return usage.Free, nil
+}
+
+// UsedPercent returns the use% of the filesystem holding path.
+func UsedPercent(path string) (float64, error) {
+	usage, err := disk.Usage(existingPath(path))
+	if err != nil {
+		return 0, fmt.Errorf("checking disk usage of %s: %w", path, err)
+	}
+	return usage.UsedPercent, nil
+}
+
+// mountFor returns the mount holding path, the one with the longest
+// matching mountpoint. Paths that do not exist yet are resolved through
+// their nearest existing parent.
+func mountFor(parts []disk.PartitionStat, path string) (disk.PartitionStat, bool) {
+	path = existingPath(path)
+
+	var best disk.PartitionStat
+	found := false
+	for _, part := range parts {
+		mp := part.Mountpoint
+		if path == mp || strings.HasPrefix(path, strings.TrimSuffix(mp, "/")+"/") {
+			if !found || len(mp) > len(best.Mountpoint) {
+				best, found = part, true
+			}
+		}
+	}
+	return best, found
+}
+
+// DiscoverMounts returns the filesystems holding the stack paths, the root
+// filesystem and every /media/* mount made by the udev automount rule.
+func DiscoverMounts(paths []StoragePath) ([]MountUsage, error) {
+	parts, err := disk.Partitions(true)
+	if err != nil {
+		return nil, fmt.Errorf("reading mounts: %w", err)
+	}
+
+	byMount := map[string]*MountUsage{}
+	add := func(part disk.PartitionStat, role string) {
+		m, ok := byMount[part.Mountpoint]
+		if !ok {
+			m = &MountUsage{Mountpoint: part.Mountpoint, Device: part.Device, Fstype: part.Fstype}
+			if usage, err := disk.Usage(part.Mountpoint); err == nil {
+				m.Total, m.Used, m.Free, m.UsedPct = usage.Total, usage.Used, usage.Free, usage.UsedPercent
+			}
+			byMount[part.Mountpoint] = m
+		}
+		if role != "" {
+			m.Roles = append(m.Roles, role)
+		}
+	}
 
-	if len(paths) == 0 {
-		paths = DefaultDiskPaths
+	for _, part := range parts {
+		if part.Mountpoint == "/" || strings.HasPrefix(part.Mountpoint, "/media/") {
+			add(part, "")
+		}
+	}
+	for _, sp := range paths {
+		if part, ok := mountFor(parts, sp.Path); ok {
+			add(part, sp.Role)
+		}
 	}
 
-	table := ui.NewTable(p.Out, "MOUNT", "USED", "TOTAL", "AVAIL", "USE%")
+	mounts := make([]MountUsage, 0, len(byMount))
+	for _, m := range byMount {
+		mounts = append(mounts, *m)
+	}
+	sort.Slice(mounts, func(i, j int) bool { return mounts[i].Mountpoint < mounts[j].Mountpoint })
+	return mounts, nil
+}
 
-	for _, path := range paths {
-		usage, err := disk.Usage(path)
-		if err != nil {
-			p.Warning(fmt.Sprintf("%s: %s", path, err))
+// SharedDevice reports whether two stack paths live on the same device.
+func SharedDevice(paths []StoragePath, a, b string) (string, bool) {
+	parts, err := disk.Partitions(true)
+	if err != nil {
+		return "", false
+	}
+	var devA, devB string
+	for _, sp := range paths {
+		part, ok := mountFor(parts, sp.Path)
+		if !ok {
 			continue
 		}
+		switch sp.Role {
+		case a:
+			devA = part.Device
+		case b:
+			devB = part.Device
+		}
+	}
+	return devA, devA != "" && devA == devB
+}
+
+// RunDisk shows disk usage for the given paths or, by default, for the
+// filesystems the stack uses and the drives mounted under /media.
+func RunDisk(p *ui.Printer, paths []string, stack []StoragePath) error {
+	p.Header("Disk Usage")
+
+	if len(paths) > 0 {
+		table := ui.NewTable(p.Out, "PATH", "DEVICE", "USED", "TOTAL", "AVAIL", "USE%")
+		parts, _ := disk.Partitions(true)
+		for _, path := range paths {
+			usage, err := disk.Usage(path)
+			if err != nil {
+				p.Warning(fmt.Sprintf("%s: %s", path, err))
+				continue
+			}
+			device := ""
+			if part, ok := mountFor(parts, path); ok {
+				device = part.Device
+			}
+			table.Row(
+				path,
+				device,
+				ui.FormatBytesSpaced(usage.Used),
+				ui.FormatBytesSpaced(usage.Total),
+				ui.FormatBytesSpaced(usage.Free),
+				getDiskPctString(usage.UsedPercent),
+			)
+		}
+		table.Flush()
+		return nil
+	}
+
+	mounts, err := DiscoverMounts(stack)
+	if err != nil {
+		return err
+	}
+	table := ui.NewTable(p.Out, "MOUNT", "DEVICE", "USED", "TOTAL", "AVAIL", "USE%", "STACK")
+	for _, m := range mounts {
 		table.Row(
-			path,
-			formatBytesHW(usage.Used),
-			formatBytesHW(usage.Total),
-			formatBytesHW(usage.Free),
-			getDiskPctString(usage.UsedPercent),
+			m.Mountpoint,
+			m.Device,
+			ui.FormatBytesSpaced(m.Used),
+			ui.FormatBytesSpaced(m.Total),
+			ui.FormatBytesSpaced(m.Free),
+			getDiskPctString(m.UsedPct),
+			strings.Join(m.Roles, ", "),
 		)
 	}
 	table.Flush()
+
+	if len(stack) > 0 {
+		p.Println("")
+		p.Println("Stack paths:")
+		table = ui.NewTable(p.Out, "ROLE", "PATH", "MOUNT")
+		parts, _ := disk.Partitions(true)
+		for _, sp := range stack {
+			mount := color.New(color.FgYellow).Sprint("missing")
+			if _, err := os.Stat(sp.Path); !errors.Is(err, os.ErrNotExist) {
+				if part, ok := mountFor(parts, sp.Path); ok {
+					mount = part.Mountpoint
+				}
+			}
+			table.Row(sp.Role, sp.Path, mount)
+		}
+		table.Flush()
+	}
+
+	if dev, shared := SharedDevice(stack, RoleConfig, RoleBackups); shared {
+		p.Println("")
+		p.Warning(fmt.Sprintf("Config and backups are both on %s: one disk failure loses both. Point BACKUP_DIR at another drive.", dev))
+	}
 	return nil
 }
 
@@ -18,15 +18,18 @@ const (
 
 // GPUInfo holds GPU monitoring data.
 type GPUInfo struct {
-	CurrentFreq    int64
-	TargetFreq     int64
-	MinFreq        int64
-	MaxFreq        int64
-	Governor       string
-	AvailableFreqs []int64
-	PowerState     string
-	FreqPct        int
-	TransStat      string
+	CurrentFreq    int64   `json:"current_freq"`
+	TargetFreq     int64   `json:"target_freq"`
+	MinFreq        int64   `json:"min_freq"`
+	MaxFreq        int64   `json:"max_freq"`
+	Governor       string  `json:"governor"`
+	AvailableFreqs []int64 `json:"available_freqs"`
+	PowerState     string  `json:"power_state"`
+	FreqPct        int     `json:"freq_pct"`
+	TransStat      string  `json:"-"`
+
+	// Residency is TransStat parsed; nil when trans_stat is unavailable.
+	Residency *FreqResidency `json:"residency,omitempty"`
 }
 
 // VPUInfo holds VPU monitoring data.
@@ -38,10 +41,10 @@ type VPUInfo struct {
 
 // VPUInterruptDelta holds interrupt delta information.
 type VPUInterruptDelta struct {
-	Name       string
-	Count      int64
-	Delta      int64
-	RatePerSec float64
+	Name       string  `json:"name"`
+	Count      int64   `json:"count"`
+	Delta      int64   `json:"delta"`
+	RatePerSec float64 `json:"rate_per_sec"`
 }
 
 // ReadGPUInfo reads GPU metrics from sysfs.
@@ -87,9 +90,10 @@ func ReadGPUInfo() GPUInfo {
 		info.PowerState = strings.TrimSpace(string(data))
 	}
 
-	// Read trans_stat (raw for future use)
+	// Read time-in-state and transition counts
 	if data, err := os.ReadFile(GPUFreqPath + "/trans_stat"); err == nil {
 		info.TransStat = string(data)
+		info.Residency, _ = ParseTransStat(info.TransStat)
 	}
 
 	return info
@@ -214,7 +218,7 @@ func getVPUActivityColor(rate float64) (*color.Color, string) {
 }
 
 // RunGPUStatus shows GPU/VPU status.
-func RunGPUStatus(p *ui.Printer) error {
+func RunGPUStatus(p *ui.Printer, containers func() map[string]string) error {
 	p.Header("GPU/VPU Status (RK3566)")
 
 	// Read GPU info
@@ -250,6 +254,15 @@ func RunGPUStatus(p *ui.Printer) error {
 		p.Warning(fmt.Sprintf("GPU frequency path not found: %s", GPUFreqPath))
 	}
 
+	// Residency
+	if r := info.Residency; r != nil {
+		p.Printf("  Residency:  %s at max clock, %d transitions since boot\n",
+			fmt.Sprintf("%.1f%%", r.Share(r.Top())*100), r.Transitions)
+		for _, line := range residencyLines(r, 24) {
+			p.Println("   " + line)
+		}
+	}
+
 	// Temperature
 	gpuTemp := ReadGPUTempDirect()
 	if gpuTemp.Valid {
@@ -297,5 +310,38 @@ func RunGPUStatus(p *ui.Printer) error {
 		p.Warning("  VPU interrupt info not available")
 	}
 
+	p.Println("")
+	printDeviceHolders(p, containers)
 	return nil
 }
+
+// printDeviceHolders shows which processes, and which containers, have the
+// GPU, VPU and RGA open, e.g. a Jellyfin ffmpeg in the middle of a transcode.
+func printDeviceHolders(p *ui.Printer, containers func() map[string]string) {
+	holders, denied := ReadDeviceHolders()
+	var names map[string]string
+	if containers != nil {
+		names = containers()
+	}
+
+	p.Println("Device users:")
+	if len(holders) == 0 {
+		p.Println("  none")
+	} else {
+		table := ui.NewTable(p.Out, "CONTAINER", "PROCESS", "PID", "DEVICE", "HOLDS")
+		for _, h := range holders {
+			owner := "(host)"
+			if h.Container != "" {
+				owner = h.Container[:12]
+				if name, ok := names[h.Container]; ok {
+					owner = name
+				}
+			}
+			table.Row(owner, h.Command, strconv.Itoa(h.PID), h.Device, h.Name)
+		}
+		table.Flush()
+	}
+	if denied > 0 {
+		p.Warning(fmt.Sprintf("%d processes could not be inspected; run as root to see all device users", denied))
+	}
+}
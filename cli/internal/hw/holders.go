@@ -0,0 +1,107 @@
+package hw
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DRMPath is the sysfs class of GPU card and render nodes.
+const DRMPath = "/sys/class/drm"
+
+// containerIDPattern matches a Docker container ID in /proc/<pid>/cgroup,
+// e.g. "0::/system.slice/docker-<id>.scope" or "4:blkio:/docker/<id>".
+var containerIDPattern = regexp.MustCompile(`[0-9a-f]{64}`)
+
+// DeviceHolder is a process with a GPU, VPU or RGA device node open.
+type DeviceHolder struct {
+	PID       int    `json:"pid"`
+	Command   string `json:"command"`
+	Device    string `json:"device"`
+	Name      string `json:"name"`                // what the node drives
+	Container string `json:"container,omitempty"` // container ID; empty on the host
+}
+
+// isAccelNode reports whether path is a video, media or DRM device node.
+func isAccelNode(path string) bool {
+	return strings.HasPrefix(path, "/dev/video") || strings.HasPrefix(path, "/dev/media") ||
+		strings.HasPrefix(path, "/dev/dri/")
+}
+
+// deviceLabels names device nodes after their driver: the V4L2 name for
+// video and media nodes and the kernel driver for DRM nodes.
+func deviceLabels() map[string]string {
+	labels := map[string]string{}
+	for _, d := range ReadVideoDevices() {
+		labels[d.Path] = d.Name
+	}
+	nodes, _ := filepath.Glob(filepath.Join(DRMPath, "*"))
+	for _, node := range nodes {
+		if driver, err := filepath.EvalSymlinks(filepath.Join(node, "device", "driver")); err == nil {
+			labels["/dev/dri/"+filepath.Base(node)] = filepath.Base(driver)
+		}
+	}
+	return labels
+}
+
+// ReadDeviceHolders scans /proc/<pid>/fd for open GPU, VPU and RGA nodes
+// and attributes each process to its container through its cgroup. It also
+// returns how many processes could not be inspected, which happens for
+// other users' processes when not running as root.
+func ReadDeviceHolders() ([]DeviceHolder, int) {
+	procs, _ := filepath.Glob("/proc/[0-9]*")
+	labels := deviceLabels()
+
+	var holders []DeviceHolder
+	denied := 0
+	for _, proc := range procs {
+		pid, err := strconv.Atoi(filepath.Base(proc))
+		if err != nil {
+			continue
+		}
+		fds, err := os.ReadDir(filepath.Join(proc, "fd"))
+		if err != nil {
+			if os.IsPermission(err) {
+				denied++
+			}
+			continue
+		}
+
+		seen := map[string]bool{}
+		for _, fd := range fds {
+			target, err := os.Readlink(filepath.Join(proc, "fd", fd.Name()))
+			if err != nil || !isAccelNode(target) || seen[target] {
+				continue
+			}
+			seen[target] = true
+			holders = append(holders, DeviceHolder{
+				PID:       pid,
+				Command:   readSysString(filepath.Join(proc, "comm")),
+				Device:    target,
+				Name:      labels[target],
+				Container: procContainer(proc),
+			})
+		}
+	}
+
+	sort.Slice(holders, func(i, j int) bool {
+		a, b := holders[i], holders[j]
+		if a.Device != b.Device {
+			return a.Device < b.Device
+		}
+		return a.PID < b.PID
+	})
+	return holders, denied
+}
+
+// procContainer returns the ID of the container a process runs in.
+func procContainer(proc string) string {
+	data, err := os.ReadFile(filepath.Join(proc, "cgroup"))
+	if err != nil {
+		return ""
+	}
+	return containerIDPattern.FindString(string(data))
+}
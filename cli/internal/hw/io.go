@@ -0,0 +1,412 @@
+package hw
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/anibalnet/blackbeard/cli/internal/ui"
+	"github.com/fatih/color"
+)
+
+// DiskStat is one line of /proc/diskstats.
+type DiskStat struct {
+	Name         string
+	Reads        uint64
+	ReadSectors  uint64
+	ReadMillis   uint64
+	Writes       uint64
+	WriteSectors uint64
+	WriteMillis  uint64
+	InFlight     uint64
+	IOMillis     uint64 // time the device had I/O in flight
+	WeightedMs   uint64 // time in flight multiplied by queue length
+}
+
+// DiskIO is throughput and latency of one device over an interval.
+type DiskIO struct {
+	Name       string  `json:"name"`
+	ReadBps    float64 `json:"read_bps"`
+	WriteBps   float64 `json:"write_bps"`
+	ReadIOPS   float64 `json:"read_iops"`
+	WriteIOPS  float64 `json:"write_iops"`
+	ReadLatMs  float64 `json:"read_latency_ms"`
+	WriteLatMs float64 `json:"write_latency_ms"`
+	QueueDepth float64 `json:"queue_depth"`
+	UtilPct    float64 `json:"util_pct"`
+}
+
+// IOCounters are cumulative bytes and operations, as in a cgroup io.stat.
+type IOCounters struct {
+	ReadBytes  uint64
+	WriteBytes uint64
+	Reads      uint64
+	Writes     uint64
+}
+
+// ContainerIO is I/O of one container on one device over an interval.
+type ContainerIO struct {
+	Name      string  `json:"name"`
+	Device    string  `json:"device"`
+	ReadBps   float64 `json:"read_bps"`
+	WriteBps  float64 `json:"write_bps"`
+	ReadIOPS  float64 `json:"read_iops"`
+	WriteIOPS float64 `json:"write_iops"`
+}
+
+// ReadDiskStats reads /proc/diskstats for whole disks, skipping partitions
+// and loop/ram/zram devices.
+func ReadDiskStats() (map[string]DiskStat, error) {
+	f, err := os.Open("/proc/diskstats")
+	if err != nil {
+		return nil, fmt.Errorf("reading disk stats: %w", err)
+	}
+	defer f.Close()
+
+	stats := map[string]DiskStat{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 14 || !IsWholeDisk(fields[2]) {
+			continue
+		}
+		n := func(i int) uint64 {
+			v, _ := strconv.ParseUint(fields[i], 10, 64)
+			return v
+		}
+		stats[fields[2]] = DiskStat{
+			Name:         fields[2],
+			Reads:        n(3),
+			ReadSectors:  n(5),
+			ReadMillis:   n(6),
+			Writes:       n(7),
+			WriteSectors: n(9),
+			WriteMillis:  n(10),
+			InFlight:     n(11),
+			IOMillis:     n(12),
+			WeightedMs:   n(13),
+		}
+	}
+	return stats, scanner.Err()
+}
+
+// IsWholeDisk reports whether name is a real block device rather than a
+// partition or a loop/ram/zram device.
+func IsWholeDisk(name string) bool {
+	for _, prefix := range []string{"loop", "ram", "zram", "dm-", "md"} {
+		if strings.HasPrefix(name, prefix) {
+			return false
+		}
+	}
+	_, err := os.Stat("/sys/block/" + name)
+	return err == nil
+}
+
+// DiskIODelta turns two diskstats readings into rates. Diskstats sectors
+// are always 512 bytes.
+func DiskIODelta(prev, cur map[string]DiskStat, elapsed time.Duration) []DiskIO {
+	secs := elapsed.Seconds()
+	if secs <= 0 {
+		return nil
+	}
+	var out []DiskIO
+	for name, c := range cur {
+		p, ok := prev[name]
+		// A re-attached device starts its counters again from zero
+		if !ok || c.before(p) {
+			continue
+		}
+		reads, writes := float64(c.Reads-p.Reads), float64(c.Writes-p.Writes)
+		io := DiskIO{
+			Name:       name,
+			ReadBps:    float64(c.ReadSectors-p.ReadSectors) * 512 / secs,
+			WriteBps:   float64(c.WriteSectors-p.WriteSectors) * 512 / secs,
+			ReadIOPS:   reads / secs,
+			WriteIOPS:  writes / secs,
+			QueueDepth: float64(c.WeightedMs-p.WeightedMs) / (secs * 1000),
+			UtilPct:    min(float64(c.IOMillis-p.IOMillis)/(secs*1000)*100, 100),
+		}
+		if reads > 0 {
+			io.ReadLatMs = float64(c.ReadMillis-p.ReadMillis) / reads
+		}
+		if writes > 0 {
+			io.WriteLatMs = float64(c.WriteMillis-p.WriteMillis) / writes
+		}
+		out = append(out, io)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// before reports whether any counter of s is below prev, which unsigned
+// subtraction would turn into a huge rate.
+func (s DiskStat) before(prev DiskStat) bool {
+	return s.Reads < prev.Reads || s.ReadSectors < prev.ReadSectors || s.ReadMillis < prev.ReadMillis ||
+		s.Writes < prev.Writes || s.WriteSectors < prev.WriteSectors || s.WriteMillis < prev.WriteMillis ||
+		s.IOMillis < prev.IOMillis || s.WeightedMs < prev.WeightedMs
+}
+
+// deviceNames maps "major:minor" to block device names.
+func deviceNames() map[string]string {
+	names := map[string]string{}
+	paths, _ := filepath.Glob("/sys/block/*/dev")
+	for _, p := range paths {
+		names[readSysString(p)] = filepath.Base(filepath.Dir(p))
+	}
+	return names
+}
+
+// containerCgroup finds the cgroup directory of a Docker container for the
+// systemd and cgroupfs drivers on cgroup v2, then v1.
+func containerCgroup(id string) (dir string, v2 bool) {
+	for _, d := range []string{
+		"/sys/fs/cgroup/system.slice/docker-" + id + ".scope",
+		"/sys/fs/cgroup/docker/" + id,
+	} {
+		if _, err := os.Stat(filepath.Join(d, "io.stat")); err == nil {
+			return d, true
+		}
+	}
+	for _, d := range []string{
+		"/sys/fs/cgroup/blkio/system.slice/docker-" + id + ".scope",
+		"/sys/fs/cgroup/blkio/docker/" + id,
+	} {
+		if _, err := os.Stat(d); err == nil {
+			return d, false
+		}
+	}
+	return "", false
+}
+
+// ReadCgroupIO returns cumulative I/O of a container per device name.
+func ReadCgroupIO(containerID string) (map[string]IOCounters, error) {
+	dir, v2 := containerCgroup(containerID)
+	if dir == "" {
+		return nil, fmt.Errorf("no cgroup found for container %.12s", containerID)
+	}
+	names := deviceNames()
+	out := map[string]IOCounters{}
+
+	if v2 {
+		// 8:0 rbytes=1 wbytes=2 rios=3 wios=4 dbytes=0 dios=0
+		data, err := os.ReadFile(filepath.Join(dir, "io.stat"))
+		if err != nil {
+			return nil, err
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			fields := strings.Fields(line)
+			name, ok := names[firstField(fields)]
+			if !ok {
+				continue
+			}
+			var c IOCounters
+			for _, kv := range fields[1:] {
+				k, v, _ := strings.Cut(kv, "=")
+				n, _ := strconv.ParseUint(v, 10, 64)
+				switch k {
+				case "rbytes":
+					c.ReadBytes = n
+				case "wbytes":
+					c.WriteBytes = n
+				case "rios":
+					c.Reads = n
+				case "wios":
+					c.Writes = n
+				}
+			}
+			out[name] = c
+		}
+		return out, nil
+	}
+
+	// cgroup v1: "8:0 Read 1234" lines in two files
+	for file, ops := range map[string]bool{
+		"blkio.throttle.io_service_bytes_recursive": false,
+		"blkio.throttle.io_serviced_recursive":      true,
+	} {
+		data, err := os.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			fields := strings.Fields(line)
+			name, ok := names[firstField(fields)]
+			if !ok || len(fields) != 3 {
+				continue
+			}
+			n, _ := strconv.ParseUint(fields[2], 10, 64)
+			c := out[name]
+			switch {
+			case fields[1] == "Read" && ops:
+				c.Reads = n
+			case fields[1] == "Write" && ops:
+				c.Writes = n
+			case fields[1] == "Read":
+				c.ReadBytes = n
+			case fields[1] == "Write":
+				c.WriteBytes = n
+			}
+			out[name] = c
+		}
+	}
+	return out, nil
+}
+
+func firstField(fields []string) string {
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
+
+// ioSampler keeps the previous counters of the host and its containers.
+type ioSampler struct {
+	containers func() map[string]string
+	at         time.Time
+	disks      map[string]DiskStat
+	names      map[string]string                // container ID -> name
+	cgroups    map[string]map[string]IOCounters // container ID -> device -> counters
+}
+
+func (s *ioSampler) read() error {
+	disks, err := ReadDiskStats()
+	if err != nil {
+		return err
+	}
+	s.at, s.disks = time.Now(), disks
+	s.names = map[string]string{}
+	s.cgroups = map[string]map[string]IOCounters{}
+	if s.containers != nil {
+		// Keyed by ID: a restarted container gets a new cgroup whose
+		// counters start again from zero
+		for id, name := range s.containers() {
+			if c, err := ReadCgroupIO(id); err == nil {
+				s.names[id], s.cgroups[id] = name, c
+			}
+		}
+	}
+	return nil
+}
+
+func (s *ioSampler) next() ([]DiskIO, []ContainerIO, error) {
+	prev := *s
+	if err := s.read(); err != nil {
+		return nil, nil, err
+	}
+	elapsed := s.at.Sub(prev.at)
+	secs := elapsed.Seconds()
+
+	var cont []ContainerIO
+	for id, devices := range s.cgroups {
+		for dev, c := range devices {
+			p, ok := prev.cgroups[id][dev]
+			if !ok || c.ReadBytes < p.ReadBytes || c.WriteBytes < p.WriteBytes || c.Reads < p.Reads || c.Writes < p.Writes {
+				continue
+			}
+			io := ContainerIO{
+				Name:      s.names[id],
+				Device:    dev,
+				ReadBps:   float64(c.ReadBytes-p.ReadBytes) / secs,
+				WriteBps:  float64(c.WriteBytes-p.WriteBytes) / secs,
+				ReadIOPS:  float64(c.Reads-p.Reads) / secs,
+				WriteIOPS: float64(c.Writes-p.Writes) / secs,
+			}
+			if io.ReadBps+io.WriteBps > 0 {
+				cont = append(cont, io)
+			}
+		}
+	}
+	sort.Slice(cont, func(i, j int) bool {
+		return cont[i].ReadBps+cont[i].WriteBps > cont[j].ReadBps+cont[j].WriteBps
+	})
+	return DiskIODelta(prev.disks, s.disks, elapsed), cont, nil
+}
+
+// RunIO shows per-device throughput, IOPS, latency, queue depth and
+// utilisation sampled over intervalSec, and which containers caused it.
+// containers maps container IDs to names and may be nil. With watch the
+// view refreshes until interrupted.
+func RunIO(ctx context.Context, p *ui.Printer, intervalSec int, watch bool, containers func() map[string]string) error {
+	ctx, cancel := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	s := &ioSampler{containers: containers}
+	if err := s.read(); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(time.Duration(intervalSec) * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		disks, cont, err := s.next()
+		if err != nil {
+			return err
+		}
+		if watch {
+			p.Printf("\033[2J\033[H")
+		}
+		printIO(p, disks, cont, containers != nil, intervalSec)
+		if !watch {
+			return nil
+		}
+	}
+}
+
+func printIO(p *ui.Printer, disks []DiskIO, cont []ContainerIO, attributed bool, intervalSec int) {
+	p.Header("Disk I/O", fmt.Sprintf("%s (%ds sample)", time.Now().Format("15:04:05"), intervalSec))
+
+	table := ui.NewTable(p.Out, "DEVICE", "READ/s", "WRITE/s", "R IOPS", "W IOPS", "R LAT", "W LAT", "QUEUE", "UTIL")
+	for _, d := range disks {
+		table.Row(d.Name,
+			formatRate(d.ReadBps), formatRate(d.WriteBps),
+			fmt.Sprintf("%.0f", d.ReadIOPS), fmt.Sprintf("%.0f", d.WriteIOPS),
+			fmt.Sprintf("%.1fms", d.ReadLatMs), fmt.Sprintf("%.1fms", d.WriteLatMs),
+			fmt.Sprintf("%.2f", d.QueueDepth), formatUtil(d.UtilPct))
+	}
+	table.Flush()
+
+	if !attributed {
+		return
+	}
+	p.Println("")
+	p.Println("By container:")
+	if len(cont) == 0 {
+		p.Println("  no container I/O in this interval")
+		return
+	}
+	table = ui.NewTable(p.Out, "CONTAINER", "DEVICE", "READ/s", "WRITE/s", "R IOPS", "W IOPS")
+	for _, c := range cont {
+		table.Row(c.Name, c.Device, formatRate(c.ReadBps), formatRate(c.WriteBps),
+			fmt.Sprintf("%.0f", c.ReadIOPS), fmt.Sprintf("%.0f", c.WriteIOPS))
+	}
+	table.Flush()
+}
+
+func formatRate(bps float64) string {
+	return ui.FormatBytesSpaced(uint64(max(bps, 0))) + "/s"
+}
+
+func formatUtil(pct float64) string {
+	s := fmt.Sprintf("%.0f%%", pct)
+	switch {
+	case pct >= 90:
+		return color.New(color.FgRed, color.Bold).Sprint(s)
+	case pct >= 70:
+		return color.New(color.FgYellow).Sprint(s)
+	}
+	return color.New(color.FgGreen).Sprint(s)
+}
@@ -0,0 +1,52 @@
+package hw
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDiskIODelta(t *testing.T) {
+	prev := map[string]DiskStat{
+		"sda": {Reads: 100, ReadSectors: 1000, ReadMillis: 50, Writes: 10, WriteSectors: 80, WriteMillis: 40, IOMillis: 100, WeightedMs: 200},
+		"sdb": {Reads: 500, ReadSectors: 9000},
+	}
+	cur := map[string]DiskStat{
+		"sda": {Reads: 120, ReadSectors: 3048, ReadMillis: 90, Writes: 20, WriteSectors: 80, WriteMillis: 60, IOMillis: 600, WeightedMs: 1200},
+		// Re-attached: counters start again from zero
+		"sdb": {Reads: 5, ReadSectors: 40},
+		// Not in the previous reading
+		"sdc": {Reads: 1},
+	}
+
+	got := DiskIODelta(prev, cur, 2*time.Second)
+	if len(got) != 1 {
+		t.Fatalf("DiskIODelta = %+v, want only sda", got)
+	}
+	want := DiskIO{
+		Name:       "sda",
+		ReadBps:    2048 * 512 / 2,
+		ReadIOPS:   10,
+		WriteIOPS:  5,
+		ReadLatMs:  2,
+		WriteLatMs: 2,
+		QueueDepth: 0.5,
+		UtilPct:    25,
+	}
+	if got[0] != want {
+		t.Errorf("DiskIODelta = %+v, want %+v", got[0], want)
+	}
+
+	if got := DiskIODelta(prev, cur, 0); got != nil {
+		t.Errorf("DiskIODelta over no time = %+v, want nil", got)
+	}
+}
+
+func TestDiskStatBefore(t *testing.T) {
+	prev := DiskStat{Reads: 10, Writes: 10, IOMillis: 10, WeightedMs: 10}
+	if (DiskStat{Reads: 11, Writes: 10, IOMillis: 10, WeightedMs: 10}).before(prev) {
+		t.Error("growing counters reported as reset")
+	}
+	if !(DiskStat{Reads: 11, Writes: 10, IOMillis: 10, WeightedMs: 9}).before(prev) {
+		t.Error("a counter that went backwards was not reported")
+	}
+}
@@ -7,6 +7,34 @@ import (
 	"github.com/shirou/gopsutil/v4/mem"
 )
 
+// MemReading is RAM and swap usage in bytes.
+type MemReading struct {
+	Total       uint64  `json:"total"`
+	Used        uint64  `json:"used"`
+	Available   uint64  `json:"available"`
+	UsedPercent float64 `json:"used_percent"`
+	SwapTotal   uint64  `json:"swap_total"`
+	SwapUsed    uint64  `json:"swap_used"`
+}
+
+// ReadMem reads RAM and swap usage.
+func ReadMem() (MemReading, error) {
+	vm, err := mem.VirtualMemory()
+	if err != nil {
+		return MemReading{}, fmt.Errorf("reading memory: %w", err)
+	}
+	r := MemReading{
+		Total:       vm.Total,
+		Used:        vm.Used,
+		Available:   vm.Available,
+		UsedPercent: vm.UsedPercent,
+	}
+	if sw, err := mem.SwapMemory(); err == nil {
+		r.SwapTotal, r.SwapUsed = sw.Total, sw.Used
+	}
+	return r, nil
+}
+
 // RunMem shows RAM and swap usage.
 func RunMem(p *ui.Printer) error {
 	p.Header("Memory")
@@ -17,16 +45,16 @@ func RunMem(p *ui.Printer) error {
 	}
 
 	p.Printf("RAM:   %s used / %s total (%.1f%%)\n",
-		formatBytesHW(vm.Used), formatBytesHW(vm.Total), vm.UsedPercent)
-	p.Printf("Avail: %s\n", formatBytesHW(vm.Available))
+		ui.FormatBytesSpaced(vm.Used), ui.FormatBytesSpaced(vm.Total), vm.UsedPercent)
+	p.Printf("Avail: %s\n", ui.FormatBytesSpaced(vm.Available))
 	p.Printf("Buffers/Cache: %s / %s\n",
-		formatBytesHW(vm.Buffers), formatBytesHW(vm.Cached))
+		ui.FormatBytesSpaced(vm.Buffers), ui.FormatBytesSpaced(vm.Cached))
 
 	sw, err := mem.SwapMemory()
 	if err == nil {
 		if sw.Total > 0 {
 			p.Printf("Swap:  %s used / %s total (%.1f%%)\n",
-				formatBytesHW(sw.Used), formatBytesHW(sw.Total), sw.UsedPercent)
+				ui.FormatBytesSpaced(sw.Used), ui.FormatBytesSpaced(sw.Total), sw.UsedPercent)
 		} else {
 			p.Println("Swap:  disabled")
 		}
@@ -34,17 +62,3 @@ func RunMem(p *ui.Printer) error {
 
 	return nil
 }
-
-// formatBytesHW formats bytes in human-readable form (1024-based).
-func formatBytesHW(b uint64) string {
-	const unit = 1024
-	if b < unit {
-		return fmt.Sprintf("%d B", b)
-	}
-	div, exp := uint64(unit), 0
-	for n := b / unit; n >= unit; n /= unit {
-		div *= unit
-		exp++
-	}
-	return fmt.Sprintf("%.1f %ciB", float64(b)/float64(div), "KMGTPE"[exp])
-}
@@ -51,7 +51,11 @@ func printTempLine(p *ui.Printer) {
 		gpu = TempReading{Label: "GPU", Valid: false}
 	}
 
-	p.Printf("[%s] CPU: %s | GPU: %s\n", timestamp, FormatTemp(cpu), FormatTemp(gpu))
+	line := fmt.Sprintf("[%s] CPU: %s | GPU: %s", timestamp, FormatTemp(cpu), FormatTemp(gpu))
+	if t := ReadThrottle(); t.Throttled {
+		line += " | " + FormatThrottle(t)
+	}
+	p.Println(line)
 }
 
 // RunGPUMonitor monitors GPU/VPU continuously until interrupted.
@@ -67,7 +71,8 @@ func RunGPUMonitor(ctx context.Context, p *ui.Printer, intervalSec int) error {
 
 	// Initial read to establish baseline
 	prevInterrupts := ReadVPUInterrupts()
-	printGPUDashboard(p, nil, intervalSec)
+	prevResidency := ReadGPUInfo().Residency
+	printGPUDashboard(p, ReadGPUInfo(), nil, nil, intervalSec)
 
 	for {
 		select {
@@ -79,124 +84,127 @@ func RunGPUMonitor(ctx context.Context, p *ui.Printer, intervalSec int) error {
 			deltas := CalculateVPUDelta(prevInterrupts, currentInterrupts, intervalSec)
 			prevInterrupts = currentInterrupts
 
+			info := ReadGPUInfo()
+			var residency *FreqResidency
+			if info.Residency != nil {
+				residency = info.Residency.Delta(prevResidency)
+				prevResidency = info.Residency
+			}
+
 			// Clear screen for dashboard effect
-			fmt.Print("\033[2J\033[H")
-			printGPUDashboard(p, deltas, intervalSec)
+			p.Printf("\033[2J\033[H")
+			printGPUDashboard(p, info, deltas, residency, intervalSec)
 		}
 	}
 }
 
-func printGPUDashboard(p *ui.Printer, vpuDeltas []VPUInterruptDelta, intervalSec int) {
+// gpuBoxWidth is the width of the gpu-monitor boxes.
+const gpuBoxWidth = 68
+
+func printGPUDashboard(p *ui.Printer, info GPUInfo, vpuDeltas []VPUInterruptDelta, residency *FreqResidency, intervalSec int) {
 	timestamp := time.Now().Format("2006-01-02 15:04:05")
 
 	p.Header("Orange Pi 3B - GPU Mali RK3566 Monitor", timestamp)
-	fmt.Println()
+	p.Println("")
 
-	info := ReadGPUInfo()
 	gpuTemp := ReadGPUTempDirect()
 
 	// GPU Status Box
-	fmt.Println("┌─ GPU Status ─────────────────────────────────────────────────────┐")
-
+	var lines []string
 	if info.MaxFreq > 0 {
 		freqColor := getFreqColor(info.FreqPct)
-		p.Printf("│ Frequency:    %s / %d MHz (%d%%)%s│\n",
+		lines = append(lines, fmt.Sprintf("Frequency:    %s / %d MHz (%d%%)",
 			freqColor.Sprintf("%3d MHz", info.CurrentFreq/1000000),
-			info.MaxFreq/1000000, info.FreqPct,
-			strings.Repeat(" ", 28-len(fmt.Sprintf("%d", info.MaxFreq/1000000))-len(fmt.Sprintf("%d", info.FreqPct))))
-
+			info.MaxFreq/1000000, info.FreqPct))
 		if info.TargetFreq > 0 {
-			p.Printf("│ Target Freq:  %d MHz%s│\n",
-				info.TargetFreq/1000000,
-				strings.Repeat(" ", 48-len(fmt.Sprintf("%d", info.TargetFreq/1000000))))
+			lines = append(lines, fmt.Sprintf("Target Freq:  %d MHz", info.TargetFreq/1000000))
 		}
-
-		p.Printf("│ Governor:     %-20s%s│\n", info.Governor, strings.Repeat(" ", 29))
+		lines = append(lines, fmt.Sprintf("Governor:     %s", info.Governor))
 	}
-
 	if gpuTemp.Valid {
-		p.Printf("│ Temperature:  %s%s│\n",
-			FormatTemp(gpuTemp),
-			strings.Repeat(" ", 49))
+		lines = append(lines, fmt.Sprintf("Temperature:  %s", FormatTemp(gpuTemp)))
 	}
-
 	if info.PowerState != "" {
-		powerColor := getPowerStateColor(info.PowerState)
-		p.Printf("│ Power State:  %s%s│\n",
-			powerColor.Sprintf("%-10s", info.PowerState),
-			strings.Repeat(" ", 45))
+		lines = append(lines, fmt.Sprintf("Power State:  %s", getPowerStateColor(info.PowerState).Sprint(info.PowerState)))
+	}
+	throttle := ReadThrottle()
+	lines = append(lines, fmt.Sprintf("Throttled:    %s", FormatThrottle(throttle)))
+	for _, r := range throttle.Reasons {
+		lines = append(lines, "  "+r)
 	}
+	printBox(p, "GPU Status", lines)
+	p.Println("")
 
-	fmt.Println("└──────────────────────────────────────────────────────────────────┘")
-	fmt.Println()
+	// Residency over the last interval
+	if info.Residency != nil {
+		if residency != nil {
+			lines = residencyLines(residency, 24)
+			lines = append(lines, fmt.Sprintf("At max clock: %.1f%%   Transitions: +%d",
+				residency.Share(residency.Top())*100, residency.Transitions))
+		} else {
+			lines = []string{"(calculating...)"}
+		}
+		printBox(p, fmt.Sprintf("GPU Residency (last %ds)", intervalSec), lines)
+		p.Println("")
+	}
 
 	// Available Frequencies
 	if len(info.AvailableFreqs) > 0 {
-		fmt.Println("Available Frequencies (MHz):")
-		p.Printf("  ")
-		for i, freq := range info.AvailableFreqs {
-			if i > 0 {
-				p.Printf(",  ")
-			}
+		p.Println("Available Frequencies (MHz):")
+		freqs := make([]string, 0, len(info.AvailableFreqs))
+		for _, freq := range info.AvailableFreqs {
 			freqMHz := freq / 1000000
 			if freq == info.CurrentFreq {
-				getFreqColor(info.FreqPct).Printf("[%d]", freqMHz)
+				freqs = append(freqs, getFreqColor(info.FreqPct).Sprintf("[%d]", freqMHz))
 			} else {
-				p.Printf("%d", freqMHz)
+				freqs = append(freqs, fmt.Sprintf("%d", freqMHz))
 			}
 		}
-		p.Printf("\n")
+		p.Println("  " + strings.Join(freqs, ",  "))
 	}
-	fmt.Println()
+	p.Println("")
 
 	// VPU/RGA Info
 	vpuInfo := ReadVPUInfo()
 
 	// Calculate overall VPU activity
 	var maxRate float64
-	if vpuDeltas != nil {
-		for _, delta := range vpuDeltas {
-			if delta.RatePerSec > maxRate {
-				maxRate = delta.RatePerSec
-			}
+	for _, delta := range vpuDeltas {
+		if delta.RatePerSec > maxRate {
+			maxRate = delta.RatePerSec
 		}
 	}
 
 	vpuColor, vpuStatus := getVPUActivityColor(maxRate)
 
-	fmt.Println("┌─ VPU Status ─────────────────────────────────────────────────────┐")
-	p.Printf("│ Activity:     %s%s│\n",
-		vpuColor.Sprintf("%-10s", vpuStatus),
-		strings.Repeat(" ", 46))
-
-	if vpuDeltas != nil && len(vpuDeltas) > 0 {
-		p.Printf("│ Interrupts/s:%s│\n", strings.Repeat(" ", 49))
+	lines = []string{fmt.Sprintf("Activity:     %s", vpuColor.Sprint(vpuStatus))}
+	if len(vpuDeltas) > 0 {
+		lines = append(lines, "Interrupts/s:")
 		for _, delta := range vpuDeltas {
 			intColor, _ := getVPUActivityColor(delta.RatePerSec)
-			p.Printf("│   %-12s %s (+%d)%s│\n",
-				delta.Name,
-				intColor.Sprintf("%.1f/s", delta.RatePerSec),
-				delta.Delta,
-				strings.Repeat(" ", 33-len(fmt.Sprintf("%.1f/s", delta.RatePerSec))-len(fmt.Sprintf("%d", delta.Delta))))
+			lines = append(lines, fmt.Sprintf("  %-12s %s (+%d)",
+				delta.Name, intColor.Sprintf("%.1f/s", delta.RatePerSec), delta.Delta))
 		}
 	} else {
-		p.Printf("│ (calculating...)%s│\n", strings.Repeat(" ", 49))
+		lines = append(lines, "(calculating...)")
 	}
 
 	// VPU Clocks
 	if len(vpuInfo.Clocks) > 0 {
-		p.Printf("│ Clocks:%s│\n", strings.Repeat(" ", 57))
+		lines = append(lines, "Clocks:")
 		for name, freq := range vpuInfo.Clocks {
-			freqMHz := freq / 1000000
-			if freqMHz > 0 {
-				color.New(color.FgGreen).Printf("│   %-18s %4d MHz%s│\n",
-					name, freqMHz, strings.Repeat(" ", 36))
-			} else {
-				p.Printf("│   %-18s %4d MHz%s│\n",
-					name, freqMHz, strings.Repeat(" ", 36))
+			line := fmt.Sprintf("  %-18s %4d MHz", name, freq/1000000)
+			if freq > 0 {
+				line = color.New(color.FgGreen).Sprint(line)
 			}
+			lines = append(lines, line)
 		}
 	}
+	printBox(p, "VPU Status", lines)
+}
 
-	fmt.Println("└──────────────────────────────────────────────────────────────────┘")
+func printBox(p *ui.Printer, title string, lines []string) {
+	for _, l := range ui.Box(title, lines, gpuBoxWidth) {
+		p.Println(l)
+	}
 }
@@ -1,37 +1,184 @@
 package hw
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/anibalnet/blackbeard/cli/internal/ui"
 	psnet "github.com/shirou/gopsutil/v4/net"
 )
 
-// RunNet shows network I/O counters per interface.
-func RunNet(p *ui.Printer) error {
-	p.Header("Network I/O")
+// NetRate is the throughput of one interface or service over an interval.
+type NetRate struct {
+	Name    string  `json:"name"`
+	RxBps   float64 `json:"rx_bps"`
+	TxBps   float64 `json:"tx_bps"`
+	RxTotal uint64  `json:"rx_total"`
+	TxTotal uint64  `json:"tx_total"`
+	Errors  uint64  `json:"errors,omitempty"` // new errors and drops in the interval
+}
 
+// ReadNetCounters returns counters of host interfaces, leaving out loopback
+// and the virtual interfaces Docker creates for containers and bridges.
+func ReadNetCounters() (map[string]psnet.IOCountersStat, error) {
 	counters, err := psnet.IOCounters(true)
 	if err != nil {
-		return fmt.Errorf("reading network counters: %w", err)
+		return nil, fmt.Errorf("reading network counters: %w", err)
+	}
+	out := map[string]psnet.IOCountersStat{}
+	for _, c := range counters {
+		if c.Name == "lo" || c.Name == "docker0" ||
+			strings.HasPrefix(c.Name, "veth") || strings.HasPrefix(c.Name, "br-") {
+			continue
+		}
+		out[c.Name] = c
+	}
+	return out, nil
+}
+
+// NetDelta turns two counter readings into rates.
+func NetDelta(prev, cur map[string]psnet.IOCountersStat, elapsed time.Duration) []NetRate {
+	secs := elapsed.Seconds()
+	var out []NetRate
+	for name, c := range cur {
+		p, ok := prev[name]
+		if !ok || secs <= 0 {
+			continue
+		}
+		// A re-created interface, such as a container's veth, starts from zero
+		drops, prevDrops := c.Errin+c.Errout+c.Dropin+c.Dropout, p.Errin+p.Errout+p.Dropin+p.Dropout
+		if c.BytesRecv < p.BytesRecv || c.BytesSent < p.BytesSent || drops < prevDrops {
+			continue
+		}
+		out = append(out, NetRate{
+			Name:    name,
+			RxBps:   float64(c.BytesRecv-p.BytesRecv) / secs,
+			TxBps:   float64(c.BytesSent-p.BytesSent) / secs,
+			RxTotal: c.BytesRecv,
+			TxTotal: c.BytesSent,
+			Errors:  drops - prevDrops,
+		})
 	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
 
-	table := ui.NewTable(p.Out, "INTERFACE", "RX", "TX", "RX PKT", "TX PKT", "RX ERR", "TX ERR")
+// ServiceNetFunc returns bytes received and sent per service since its
+// containers started.
+type ServiceNetFunc func() map[string][2]uint64
 
-	for _, c := range counters {
-		if c.Name == "lo" {
+// RunNet shows per-interface throughput sampled over intervalSec and, when
+// services is set, bandwidth by compose service. With watch the view
+// refreshes until interrupted.
+func RunNet(ctx context.Context, p *ui.Printer, intervalSec int, watch bool, services ServiceNetFunc) error {
+	ctx, cancel := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	prev, err := ReadNetCounters()
+	if err != nil {
+		return err
+	}
+	var prevSvc map[string][2]uint64
+	if services != nil {
+		prevSvc = services()
+	}
+	prevAt := time.Now()
+
+	ticker := time.NewTicker(time.Duration(intervalSec) * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		cur, err := ReadNetCounters()
+		if err != nil {
+			return err
+		}
+		var curSvc map[string][2]uint64
+		if services != nil {
+			curSvc = services()
+		}
+		elapsed := time.Since(prevAt)
+		prevAt = time.Now()
+
+		ifaces := NetDelta(prev, cur, elapsed)
+		svc := serviceRates(prevSvc, curSvc, elapsed)
+		prev, prevSvc = cur, curSvc
+
+		if watch {
+			p.Printf("\033[2J\033[H")
+		}
+		printNet(p, ifaces, svc, services != nil, intervalSec)
+		if !watch {
+			return nil
+		}
+	}
+}
+
+func serviceRates(prev, cur map[string][2]uint64, elapsed time.Duration) []NetRate {
+	secs := elapsed.Seconds()
+	var out []NetRate
+	for name, c := range cur {
+		p, ok := prev[name]
+		// Counters restart with the container; skip until the next interval
+		if !ok || c[0] < p[0] || c[1] < p[1] || secs <= 0 {
 			continue
 		}
-		table.Row(
-			c.Name,
-			formatBytesHW(c.BytesRecv),
-			formatBytesHW(c.BytesSent),
-			fmt.Sprintf("%d", c.PacketsRecv),
-			fmt.Sprintf("%d", c.PacketsSent),
-			fmt.Sprintf("%d", c.Errin),
-			fmt.Sprintf("%d", c.Errout),
-		)
+		out = append(out, NetRate{
+			Name:    name,
+			RxBps:   float64(c[0]-p[0]) / secs,
+			TxBps:   float64(c[1]-p[1]) / secs,
+			RxTotal: c[0],
+			TxTotal: c[1],
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].RxBps+out[i].TxBps > out[j].RxBps+out[j].TxBps ||
+			(out[i].RxBps+out[i].TxBps == out[j].RxBps+out[j].TxBps && out[i].Name < out[j].Name)
+	})
+	return out
+}
+
+func printNet(p *ui.Printer, ifaces, services []NetRate, attributed bool, intervalSec int) {
+	p.Header("Network I/O", fmt.Sprintf("%s (%ds sample)", time.Now().Format("15:04:05"), intervalSec))
+
+	var hostTx float64
+	table := ui.NewTable(p.Out, "INTERFACE", "RX/s", "TX/s", "RX TOTAL", "TX TOTAL", "ERR/DROP")
+	for _, n := range ifaces {
+		hostTx += n.TxBps
+		table.Row(n.Name, formatRate(n.RxBps), formatRate(n.TxBps),
+			ui.FormatBytesSpaced(n.RxTotal), ui.FormatBytesSpaced(n.TxTotal), fmt.Sprintf("%d", n.Errors))
+	}
+	table.Flush()
+
+	if !attributed {
+		return
+	}
+	p.Println("")
+	p.Println("By service:")
+	if len(services) == 0 {
+		p.Println("  no container traffic")
+		return
+	}
+	table = ui.NewTable(p.Out, "SERVICE", "RX/s", "TX/s", "UPLINK", "RX TOTAL", "TX TOTAL")
+	for _, s := range services {
+		uplink := "-"
+		if hostTx > 0 {
+			uplink = formatUtil(min(s.TxBps/hostTx*100, 100))
+		}
+		table.Row(s.Name, formatRate(s.RxBps), formatRate(s.TxBps), uplink,
+			ui.FormatBytesSpaced(s.RxTotal), ui.FormatBytesSpaced(s.TxTotal))
 	}
 	table.Flush()
-	return nil
+	p.Println("")
+	p.Println("UPLINK is the service's share of host transmit; totals are since the container started.")
 }
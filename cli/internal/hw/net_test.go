@@ -0,0 +1,40 @@
+package hw
+
+import (
+	"testing"
+	"time"
+
+	psnet "github.com/shirou/gopsutil/v4/net"
+)
+
+func TestNetDelta(t *testing.T) {
+	prev := map[string]psnet.IOCountersStat{
+		"eth0":  {BytesRecv: 1000, BytesSent: 500, Errin: 1},
+		"wlan0": {BytesRecv: 9000, BytesSent: 9000},
+	}
+	cur := map[string]psnet.IOCountersStat{
+		"eth0": {BytesRecv: 5000, BytesSent: 1500, Errin: 2, Dropout: 1},
+		// Re-created interface: counters start again from zero
+		"wlan0": {BytesRecv: 10, BytesSent: 10},
+		"usb0":  {BytesRecv: 10},
+	}
+
+	got := NetDelta(prev, cur, 2*time.Second)
+	want := []NetRate{{Name: "eth0", RxBps: 2000, TxBps: 500, RxTotal: 5000, TxTotal: 1500, Errors: 2}}
+	if len(got) != len(want) || got[0] != want[0] {
+		t.Errorf("NetDelta = %+v, want %+v", got, want)
+	}
+}
+
+func TestServiceRates(t *testing.T) {
+	prev := map[string][2]uint64{"jellyfin": {100, 100}, "sonarr": {50, 50}, "radarr": {900, 900}}
+	cur := map[string][2]uint64{"jellyfin": {300, 1100}, "sonarr": {150, 150}, "radarr": {10, 10}}
+
+	got := serviceRates(prev, cur, time.Second)
+	if len(got) != 2 || got[0].Name != "jellyfin" || got[1].Name != "sonarr" {
+		t.Fatalf("serviceRates = %+v, want jellyfin then sonarr", got)
+	}
+	if got[0].RxBps != 200 || got[0].TxBps != 1000 {
+		t.Errorf("jellyfin = %+v, want 200 rx and 1000 tx per second", got[0])
+	}
+}
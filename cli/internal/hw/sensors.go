@@ -0,0 +1,168 @@
+package hw
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const HwmonPath = "/sys/class/hwmon"
+
+// Sensor groups, in display order.
+var sensorGroups = []string{"cpu", "gpu", "soc", "disk", "pmic", "other"}
+
+// Sensor is one temperature reading from a thermal zone or hwmon device.
+type Sensor struct {
+	Name    string  `json:"name"`  // stable ID, e.g. "cpu-thermal" or "nvme/Composite"
+	Label   string  `json:"label"` // user label from sensors.yml, or Name
+	Group   string  `json:"group"`
+	Source  string  `json:"source"` // "thermal" or "hwmon"
+	Celsius float64 `json:"celsius"`
+}
+
+// Reading returns the sensor as a TempReading for formatting.
+func (s Sensor) Reading() TempReading {
+	return TempReading{Label: s.Label, Celsius: s.Celsius, Valid: true}
+}
+
+// SensorConfig holds user labels and groups keyed by sensor name.
+//
+//	sensors:
+//	  cpu-thermal:    {label: SoC}
+//	  nvme/Composite: {label: NVMe SSD, group: disk}
+type SensorConfig struct {
+	Sensors map[string]struct {
+		Label string `yaml:"label"`
+		Group string `yaml:"group"`
+	} `yaml:"sensors"`
+}
+
+// LoadSensorConfig reads a sensors.yml file. A missing file is not an error.
+func LoadSensorConfig(path string) (*SensorConfig, error) {
+	cfg := &SensorConfig{}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// ReadSensors enumerates every thermal zone and hwmon temperature input.
+// hwmon devices that only mirror a thermal zone are skipped. cfg may be nil.
+func ReadSensors(cfg *SensorConfig) []Sensor {
+	var sensors []Sensor
+	seen := map[string]bool{}
+	add := func(s Sensor) {
+		seen[s.Name] = true
+		sensors = append(sensors, s)
+	}
+
+	zoneTypes := map[string]bool{}
+	for _, z := range ReadThermalZones() {
+		zoneTypes[z.Type] = true
+		if z.Celsius <= 0 {
+			continue
+		}
+		name := z.Type
+		if name == "" || seen[name] {
+			name = z.Name
+		}
+		add(Sensor{Name: name, Source: "thermal", Celsius: z.Celsius})
+	}
+
+	for _, dir := range sortedGlob(filepath.Join(HwmonPath, "hwmon*"), "hwmon") {
+		device := readSysString(filepath.Join(dir, "name"))
+		if zoneTypes[strings.ReplaceAll(device, "_", "-")] || zoneTypes[device] {
+			continue
+		}
+		inputs, _ := filepath.Glob(filepath.Join(dir, "temp*_input"))
+		sort.Strings(inputs)
+		for _, input := range inputs {
+			milli := readSysInt(input)
+			if milli <= 0 {
+				continue
+			}
+			channel := strings.TrimSuffix(filepath.Base(input), "_input")
+			label := readSysString(filepath.Join(dir, channel+"_label"))
+			if label == "" {
+				label = channel
+			}
+			name := device + "/" + label
+			if seen[name] {
+				// A second device of the same kind, e.g. two NVMe drives
+				name = device + "@" + filepath.Base(dir) + "/" + label
+			}
+			add(Sensor{Name: name, Source: "hwmon", Celsius: float64(milli) / 1000})
+		}
+	}
+
+	for i := range sensors {
+		s := &sensors[i]
+		s.Label, s.Group = s.Name, sensorGroup(s.Name)
+		if cfg == nil {
+			continue
+		}
+		if c, ok := cfg.Sensors[s.Name]; ok {
+			if c.Label != "" {
+				s.Label = c.Label
+			}
+			if c.Group != "" {
+				s.Group = c.Group
+			}
+		}
+	}
+
+	order := func(group string) int {
+		for i, g := range sensorGroups {
+			if g == group {
+				return i
+			}
+		}
+		return len(sensorGroups)
+	}
+	sort.SliceStable(sensors, func(i, j int) bool {
+		return order(sensors[i].Group) < order(sensors[j].Group)
+	})
+	return sensors
+}
+
+// FindSensor returns the sensor whose name or label matches, ignoring case.
+func FindSensor(sensors []Sensor, name string) (Sensor, bool) {
+	for _, s := range sensors {
+		if strings.EqualFold(s.Name, name) || strings.EqualFold(s.Label, name) {
+			return s, true
+		}
+	}
+	return Sensor{}, false
+}
+
+// sensorGroup guesses a group from a sensor name. On RK3566 the thermal
+// zones are soc-thermal (the CPU cluster) and gpu-thermal.
+func sensorGroup(name string) string {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.Contains(lower, "cpu") || strings.Contains(lower, "core") || strings.HasPrefix(lower, "soc"):
+		return "cpu"
+	case strings.Contains(lower, "gpu"):
+		return "gpu"
+	case strings.Contains(lower, "nvme") || strings.Contains(lower, "drivetemp") ||
+		strings.Contains(lower, "sata") || strings.Contains(lower, "disk"):
+		return "disk"
+	case strings.Contains(lower, "pmic") || strings.HasPrefix(lower, "rk8") ||
+		strings.Contains(lower, "battery") || strings.Contains(lower, "charger"):
+		return "pmic"
+	case strings.Contains(lower, "ddr") || strings.Contains(lower, "npu") || strings.Contains(lower, "center"):
+		return "soc"
+	}
+	return "other"
+}
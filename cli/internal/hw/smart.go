@@ -0,0 +1,417 @@
+package hw
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/anibalnet/blackbeard/cli/internal/ui"
+	"github.com/fatih/color"
+)
+
+// SMART verdicts, from best to worst.
+const (
+	SmartOK      = "OK"
+	SmartWarning = "WARNING"
+	SmartFailing = "FAILING"
+	SmartUnknown = "UNKNOWN"
+)
+
+// Attributes that predict drive failure or matter on a media drive.
+const (
+	AttrReallocated  = 5
+	AttrPowerOnHours = 9
+	AttrUncorrect    = 187
+	AttrAirflowTemp  = 190
+	AttrTemperature  = 194
+	AttrPending      = 197
+	AttrOffline      = 198
+	AttrCRCErrors    = 199
+)
+
+var attrNames = map[int]string{
+	1:   "Raw_Read_Error_Rate",
+	3:   "Spin_Up_Time",
+	4:   "Start_Stop_Count",
+	5:   "Reallocated_Sector_Ct",
+	7:   "Seek_Error_Rate",
+	9:   "Power_On_Hours",
+	10:  "Spin_Retry_Count",
+	12:  "Power_Cycle_Count",
+	183: "Runtime_Bad_Block",
+	184: "End-to-End_Error",
+	187: "Reported_Uncorrect",
+	188: "Command_Timeout",
+	190: "Airflow_Temperature",
+	192: "Power-Off_Retract_Count",
+	193: "Load_Cycle_Count",
+	194: "Temperature_Celsius",
+	196: "Reallocated_Event_Count",
+	197: "Current_Pending_Sector",
+	198: "Offline_Uncorrectable",
+	199: "UDMA_CRC_Error_Count",
+	240: "Head_Flying_Hours",
+	241: "Total_LBAs_Written",
+	242: "Total_LBAs_Read",
+}
+
+// sectorAttrs are attributes whose raw value should stay at zero.
+var sectorAttrs = []int{AttrReallocated, AttrUncorrect, AttrPending, AttrOffline}
+
+// SmartAttr is one SMART attribute with its normalised and raw values.
+type SmartAttr struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	Value     int    `json:"value"`
+	Worst     int    `json:"worst"`
+	Threshold int    `json:"threshold"`
+	Raw       int64  `json:"raw"`
+	PreFail   bool   `json:"prefail"`
+}
+
+// Failing reports whether the normalised value has reached the threshold.
+func (a SmartAttr) Failing() bool {
+	return a.Threshold > 0 && a.Value <= a.Threshold
+}
+
+// SmartDrive is the SMART state of one drive.
+type SmartDrive struct {
+	Device    string      `json:"device"`
+	Model     string      `json:"model"`
+	Serial    string      `json:"serial"`
+	Firmware  string      `json:"firmware"`
+	Transport string      `json:"transport"` // "sat16" or "sat12" passthrough
+	Passed    *bool       `json:"passed,omitempty"`
+	Attrs     []SmartAttr `json:"attrs"`
+	Verdict   string      `json:"verdict"`
+	Reasons   []string    `json:"reasons,omitempty"`
+	Err       string      `json:"error,omitempty"`
+}
+
+// Attr returns the attribute with the given ID.
+func (d *SmartDrive) Attr(id int) (SmartAttr, bool) {
+	for _, a := range d.Attrs {
+		if a.ID == id {
+			return a, true
+		}
+	}
+	return SmartAttr{}, false
+}
+
+// Temperature returns the drive temperature in °C, if reported.
+func (d *SmartDrive) Temperature() (int, bool) {
+	for _, id := range []int{AttrTemperature, AttrAirflowTemp} {
+		if a, ok := d.Attr(id); ok {
+			// The low byte is the current temperature, the rest min/max
+			return int(a.Raw & 0xff), true
+		}
+	}
+	return 0, false
+}
+
+// SmartSnapshot is a stored reading used to spot attributes that change.
+type SmartSnapshot struct {
+	Time     time.Time     `json:"time"`
+	Verdict  string        `json:"verdict"`
+	Raw      map[int]int64 `json:"raw"`
+	Baseline bool          `json:"baseline,omitempty"` // accepted with hw smart --ack
+}
+
+// smartBaseline returns the snapshot counters are compared with: the last
+// one accepted with --ack, else the first. Comparing with the latest would
+// clear a warning as soon as the raised value was recorded.
+func smartBaseline(snaps []SmartSnapshot) *SmartSnapshot {
+	if len(snaps) == 0 {
+		return nil
+	}
+	for i := len(snaps) - 1; i >= 0; i-- {
+		if snaps[i].Baseline {
+			return &snaps[i]
+		}
+	}
+	return &snaps[0]
+}
+
+// SmartDevices lists the SCSI/SATA disks (including USB drives) to check.
+func SmartDevices() []string {
+	paths, _ := filepath.Glob("/sys/block/sd*")
+	devices := make([]string, 0, len(paths))
+	for _, p := range paths {
+		devices = append(devices, "/dev/"+filepath.Base(p))
+	}
+	sort.Strings(devices)
+	return devices
+}
+
+// ReadSmart reads SMART data from a drive and assigns a verdict, comparing
+// with the baseline snapshot in stateDir when given.
+func ReadSmart(device, stateDir string) *SmartDrive {
+	d, err := readSmart(device)
+	if err != nil {
+		if d == nil {
+			d = &SmartDrive{Device: device}
+		}
+		d.Verdict = SmartUnknown
+		d.Err = err.Error()
+		return d
+	}
+	var base *SmartSnapshot
+	if stateDir != "" && d.Serial != "" {
+		snaps, _ := SmartHistory(stateDir, d.Serial)
+		base = smartBaseline(snaps)
+	}
+	assess(d, base)
+	return d
+}
+
+// assess sets the verdict from the SMART status, thresholds, sector counts,
+// temperature and changes since the baseline snapshot.
+func assess(d *SmartDrive, base *SmartSnapshot) {
+	d.Verdict = SmartOK
+	fail := func(reason string) {
+		d.Verdict = SmartFailing
+		d.Reasons = append(d.Reasons, reason)
+	}
+	warn := func(reason string) {
+		if d.Verdict == SmartOK {
+			d.Verdict = SmartWarning
+		}
+		d.Reasons = append(d.Reasons, reason)
+	}
+
+	if d.Passed != nil && !*d.Passed {
+		fail("drive reports SMART status FAILED")
+	}
+	for _, a := range d.Attrs {
+		if a.Failing() && a.PreFail {
+			fail(fmt.Sprintf("%s at %d, threshold %d", a.Name, a.Value, a.Threshold))
+		}
+	}
+	for _, id := range sectorAttrs {
+		if a, ok := d.Attr(id); ok && a.Raw > 0 {
+			warn(fmt.Sprintf("%s = %d", a.Name, a.Raw))
+		}
+	}
+	if base != nil {
+		for _, id := range append(sectorAttrs, AttrCRCErrors) {
+			a, ok := d.Attr(id)
+			if before, seen := base.Raw[id]; ok && seen && a.Raw > before {
+				warn(fmt.Sprintf("%s rose from %d to %d since %s", a.Name, before, a.Raw, base.Time.Format("Jan 02")))
+			}
+		}
+	}
+	if t, ok := d.Temperature(); ok && t >= 55 {
+		warn(fmt.Sprintf("running hot at %d°C", t))
+	}
+}
+
+func smartHistoryPath(stateDir, serial string) string {
+	safe := strings.Map(func(r rune) rune {
+		if r == '/' || r == ' ' || r == os.PathSeparator {
+			return '_'
+		}
+		return r
+	}, serial)
+	return filepath.Join(stateDir, "smart", safe+".jsonl")
+}
+
+// RecordSmart appends a snapshot of the drive to its history in stateDir.
+func RecordSmart(stateDir string, d *SmartDrive) error {
+	return recordSmart(stateDir, d, false)
+}
+
+// AcknowledgeSmart records the drive's current values as the baseline, so
+// counters that rose up to now stop raising a warning.
+func AcknowledgeSmart(stateDir string, d *SmartDrive) error {
+	return recordSmart(stateDir, d, true)
+}
+
+func recordSmart(stateDir string, d *SmartDrive, baseline bool) error {
+	if d.Serial == "" || d.Err != "" {
+		return nil
+	}
+	path := smartHistoryPath(stateDir, d.Serial)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	snap := SmartSnapshot{Time: time.Now(), Verdict: d.Verdict, Raw: map[int]int64{}, Baseline: baseline}
+	for _, a := range d.Attrs {
+		snap.Raw[a.ID] = a.Raw
+	}
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+// SmartHistory returns every stored snapshot of a drive, oldest first.
+func SmartHistory(stateDir, serial string) ([]SmartSnapshot, error) {
+	f, err := os.Open(smartHistoryPath(stateDir, serial))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var snaps []SmartSnapshot
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var s SmartSnapshot
+		if json.Unmarshal(scanner.Bytes(), &s) == nil {
+			snaps = append(snaps, s)
+		}
+	}
+	return snaps, scanner.Err()
+}
+
+// FormatVerdict returns a color-coded verdict.
+func FormatVerdict(verdict string) string {
+	switch verdict {
+	case SmartOK:
+		return color.New(color.FgGreen).Sprint(verdict)
+	case SmartWarning:
+		return color.New(color.FgYellow).Sprint(verdict)
+	case SmartFailing:
+		return color.New(color.FgRed, color.Bold).Sprint(verdict)
+	}
+	return color.New(color.FgHiBlack).Sprint(verdict)
+}
+
+// RunSmart shows SMART health for the given drives (default: every sd*
+// drive), records a snapshot of each and fails when a drive is failing.
+// With ack the snapshot becomes the baseline later readings compare with.
+func RunSmart(p *ui.Printer, devices []string, stateDir string, all, ack bool) error {
+	p.Header("Disk Health (SMART)")
+
+	if len(devices) == 0 {
+		devices = SmartDevices()
+	}
+	if len(devices) == 0 {
+		p.Warning("No SATA or USB drives found")
+		return nil
+	}
+
+	failing := 0
+	for i, device := range devices {
+		if i > 0 {
+			p.Println("")
+		}
+		d := ReadSmart(device, stateDir)
+		printSmartDrive(p, d, all)
+		if d.Verdict == SmartFailing {
+			failing++
+		}
+		if err := recordSmart(stateDir, d, ack); err != nil {
+			p.Warning(fmt.Sprintf("saving SMART history: %s", err))
+		} else if ack && d.Serial != "" && d.Err == "" {
+			p.Info(fmt.Sprintf("Current values of %s are now the baseline", d.Serial))
+		}
+	}
+
+	if failing > 0 {
+		return fmt.Errorf("%d drive(s) failing: back up now and replace", failing)
+	}
+	return nil
+}
+
+func printSmartDrive(p *ui.Printer, d *SmartDrive, all bool) {
+	p.Printf("%s  %s  %s\n", color.New(color.Bold).Sprint(d.Device), d.Model, FormatVerdict(d.Verdict))
+	if d.Err != "" {
+		p.Printf("  %s\n", d.Err)
+		return
+	}
+	p.Printf("  Serial: %s  Firmware: %s  Via: %s\n", d.Serial, d.Firmware, d.Transport)
+	if a, ok := d.Attr(AttrPowerOnHours); ok {
+		p.Printf("  Power on: %s\n", formatUptime(uint64(a.Raw&0xffffffff)*3600))
+	}
+	if t, ok := d.Temperature(); ok {
+		p.Printf("  Temperature: %s\n", FormatTemp(TempReading{Celsius: float64(t), Valid: true}))
+	}
+	for _, r := range d.Reasons {
+		p.Printf("  - %s\n", r)
+	}
+
+	table := ui.NewTable(p.Out, "ID", "ATTRIBUTE", "VALUE", "WORST", "THRESH", "RAW")
+	for _, a := range d.Attrs {
+		key := a.ID == AttrPowerOnHours || a.ID == AttrTemperature || a.ID == AttrCRCErrors ||
+			slices.Contains(sectorAttrs, a.ID)
+		if !all && !key && !a.Failing() {
+			continue
+		}
+		raw := fmt.Sprintf("%d", a.Raw)
+		if a.ID == AttrTemperature || a.ID == AttrAirflowTemp {
+			raw = fmt.Sprintf("%d", a.Raw&0xff)
+		}
+		if a.Failing() || (a.Raw > 0 && slices.Contains(sectorAttrs, a.ID)) {
+			raw = color.New(color.FgRed).Sprint(raw)
+		}
+		table.Row(fmt.Sprintf("%d", a.ID), a.Name, fmt.Sprintf("%d", a.Value),
+			fmt.Sprintf("%d", a.Worst), fmt.Sprintf("%d", a.Threshold), raw)
+	}
+	table.Flush()
+}
+
+// ErrNoSmart is returned for drives that do not answer ATA passthrough.
+var ErrNoSmart = errors.New("SMART not available (drive or USB bridge does not support ATA passthrough)")
+
+// parseSmartData decodes the 512-byte SMART READ DATA and READ THRESHOLDS
+// sectors into attributes.
+func parseSmartData(data, thresholds []byte) []SmartAttr {
+	limits := map[int]int{}
+	for i := 0; i < 30 && len(thresholds) >= 2+12*(i+1); i++ {
+		e := thresholds[2+12*i:]
+		if e[0] != 0 {
+			limits[int(e[0])] = int(e[1])
+		}
+	}
+
+	var attrs []SmartAttr
+	for i := 0; i < 30 && len(data) >= 2+12*(i+1); i++ {
+		e := data[2+12*i:]
+		id := int(e[0])
+		if id == 0 {
+			continue
+		}
+		var raw int64
+		for b := 5; b >= 0; b-- {
+			raw = raw<<8 | int64(e[5+b])
+		}
+		name, ok := attrNames[id]
+		if !ok {
+			name = fmt.Sprintf("Unknown_Attribute_%d", id)
+		}
+		attrs = append(attrs, SmartAttr{
+			ID:        id,
+			Name:      name,
+			Value:     int(e[3]),
+			Worst:     int(e[4]),
+			Threshold: limits[id],
+			Raw:       raw,
+			PreFail:   e[1]&1 != 0,
+		})
+	}
+	return attrs
+}
+
+// ataString decodes an IDENTIFY DEVICE string, which stores two characters
+// per word with the bytes swapped.
+func ataString(identify []byte, word, words int) string {
+	b := make([]byte, 0, words*2)
+	for i := word; i < word+words && 2*i+1 < len(identify); i++ {
+		b = append(b, identify[2*i+1], identify[2*i])
+	}
+	return strings.TrimSpace(string(b))
+}
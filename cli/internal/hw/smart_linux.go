@@ -0,0 +1,204 @@
+package hw
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	sgIO            = 0x2285
+	sgDxferNone     = -1
+	sgDxferFromDev  = -3
+	sgInterfaceID   = 'S'
+	sgTimeoutMillis = 15000
+
+	ataIdentify = 0xec
+	ataSmart    = 0xb0
+
+	smartReadData     = 0xd0
+	smartReadThresh   = 0xd1
+	smartReturnStatus = 0xda
+)
+
+// sgIOHdr mirrors struct sg_io_hdr from <scsi/sg.h>.
+type sgIOHdr struct {
+	interfaceID    int32
+	dxferDirection int32
+	cmdLen         uint8
+	mxSbLen        uint8
+	iovecCount     uint16
+	dxferLen       uint32
+	dxferp         uintptr
+	cmdp           uintptr
+	sbp            uintptr
+	timeout        uint32
+	flags          uint32
+	packID         int32
+	usrPtr         uintptr
+	status         uint8
+	maskedStatus   uint8
+	msgStatus      uint8
+	sbLenWr        uint8
+	hostStatus     uint16
+	driverStatus   uint16
+	resid          int32
+	duration       uint32
+	info           uint32
+}
+
+// ataCmd is an ATA command sent through SCSI/ATA Translation (SAT).
+type ataCmd struct {
+	command  byte
+	features byte
+	count    byte
+	lbaLow   byte
+	lbaMid   byte
+	lbaHigh  byte
+	dataIn   bool // PIO data-in of one 512-byte sector, else non-data
+}
+
+// cdb builds an ATA PASS-THROUGH(16) or (12) command block. Most USB-SATA
+// bridges accept the 16-byte form; some older ones only the 12-byte one.
+func (c ataCmd) cdb(size int) []byte {
+	protocol, flags := byte(3), byte(0x20) // non-data, CK_COND to return registers
+	if c.dataIn {
+		protocol, flags = 4, 0x0e // PIO data-in, T_DIR in, BYTE_BLOCK, length in count
+	}
+	if size == 16 {
+		return []byte{0x85, protocol << 1, flags, 0, c.features, 0, c.count,
+			0, c.lbaLow, 0, c.lbaMid, 0, c.lbaHigh, 0, c.command, 0}
+	}
+	return []byte{0xa1, protocol << 1, flags, c.features, c.count,
+		c.lbaLow, c.lbaMid, c.lbaHigh, 0, c.command, 0, 0}
+}
+
+// passthrough sends an ATA command and returns the data sector (for data-in
+// commands) and the sense buffer.
+func passthrough(f *os.File, c ataCmd, size int) ([]byte, []byte, error) {
+	cdb := c.cdb(size)
+	sense := make([]byte, 32)
+	var data []byte
+	hdr := sgIOHdr{
+		interfaceID:    sgInterfaceID,
+		dxferDirection: sgDxferNone,
+		cmdLen:         uint8(len(cdb)),
+		mxSbLen:        uint8(len(sense)),
+		cmdp:           uintptr(unsafe.Pointer(&cdb[0])),
+		sbp:            uintptr(unsafe.Pointer(&sense[0])),
+		timeout:        sgTimeoutMillis,
+	}
+	if c.dataIn {
+		data = make([]byte, 512)
+		hdr.dxferDirection = sgDxferFromDev
+		hdr.dxferLen = uint32(len(data))
+		hdr.dxferp = uintptr(unsafe.Pointer(&data[0]))
+	}
+
+	_, _, errno := unix.Syscall(unix.SYS_IOCTL, f.Fd(), sgIO, uintptr(unsafe.Pointer(&hdr)))
+	runtime.KeepAlive(cdb)
+	runtime.KeepAlive(sense)
+	runtime.KeepAlive(data)
+	if errno != 0 {
+		return nil, nil, errno
+	}
+
+	sense = sense[:hdr.sbLenWr]
+	if hdr.hostStatus != 0 || hdr.driverStatus&^driverSense != 0 || (hdr.status != 0 && !informational(sense)) {
+		return nil, nil, fmt.Errorf("passthrough rejected (status %#x, host %#x, driver %#x)",
+			hdr.status, hdr.hostStatus, hdr.driverStatus)
+	}
+	return data, sense, nil
+}
+
+// driverSense is set in driver_status whenever sense data was returned.
+const driverSense = 0x08
+
+// informational reports whether a CHECK CONDITION only carries the ATA
+// registers (NO SENSE or RECOVERED ERROR), as CK_COND requests and some
+// bridges send for every passthrough command.
+func informational(sense []byte) bool {
+	var key byte
+	switch {
+	case len(sense) >= 2 && sense[0]&0x7f >= 0x72:
+		key = sense[1] & 0x0f
+	case len(sense) >= 3:
+		key = sense[2] & 0x0f
+	default:
+		return false
+	}
+	return key == 0 || key == 1
+}
+
+// smartStatus decodes the LBA mid/high registers of SMART RETURN STATUS
+// from descriptor or fixed format sense data.
+func smartStatus(sense []byte) (passed, ok bool) {
+	var mid, high byte
+	switch {
+	case len(sense) >= 22 && sense[0]&0x7f == 0x72 && sense[8] == 0x09:
+		mid, high = sense[8+9], sense[8+11]
+	case len(sense) >= 12 && sense[0]&0x7f == 0x70:
+		mid, high = sense[10], sense[11]
+	default:
+		return false, false
+	}
+	switch {
+	case mid == 0x4f && high == 0xc2:
+		return true, true
+	case mid == 0xf4 && high == 0x2c:
+		return false, true
+	}
+	return false, false
+}
+
+func readSmart(device string) (*SmartDrive, error) {
+	d := &SmartDrive{Device: device}
+
+	f, err := os.OpenFile(device, os.O_RDONLY|unix.O_NONBLOCK, 0)
+	if err != nil {
+		if errors.Is(err, os.ErrPermission) {
+			return d, fmt.Errorf("%s: permission denied (SMART needs root)", device)
+		}
+		return d, err
+	}
+	defer f.Close()
+
+	// Find a passthrough size the bridge understands with IDENTIFY DEVICE
+	var identify []byte
+	size := 16
+	for _, s := range []int{16, 12} {
+		if identify, _, err = passthrough(f, ataCmd{command: ataIdentify, count: 1, dataIn: true}, s); err == nil {
+			size = s
+			break
+		}
+	}
+	if err != nil {
+		return d, ErrNoSmart
+	}
+	d.Transport = fmt.Sprintf("sat%d", size)
+	d.Serial = ataString(identify, 10, 10)
+	d.Firmware = ataString(identify, 23, 4)
+	d.Model = ataString(identify, 27, 20)
+
+	smart := func(feature byte, dataIn bool) ([]byte, []byte, error) {
+		return passthrough(f, ataCmd{command: ataSmart, features: feature, count: 1,
+			lbaMid: 0x4f, lbaHigh: 0xc2, dataIn: dataIn}, size)
+	}
+	data, _, err := smart(smartReadData, true)
+	if err != nil {
+		return d, fmt.Errorf("reading SMART data: %w (is SMART enabled on the drive?)", err)
+	}
+	thresholds, _, _ := smart(smartReadThresh, true)
+	d.Attrs = parseSmartData(data, thresholds)
+
+	if _, sense, err := smart(smartReturnStatus, false); err == nil {
+		if passed, ok := smartStatus(sense); ok {
+			d.Passed = &passed
+		}
+	}
+	return d, nil
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package hw
+
+import "fmt"
+
+func readSmart(device string) (*SmartDrive, error) {
+	return &SmartDrive{Device: device}, fmt.Errorf("%s: SMART is only supported on Linux", device)
+}
@@ -0,0 +1,158 @@
+package hw
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// smartEntry writes one 12-byte attribute entry of a SMART data sector.
+func smartEntry(sector []byte, i, id int, flags byte, value, worst int, raw int64) {
+	e := sector[2+12*i:]
+	e[0], e[1], e[3], e[4] = byte(id), flags, byte(value), byte(worst)
+	for b := 0; b < 6; b++ {
+		e[5+b] = byte(raw >> (8 * b))
+	}
+}
+
+func TestParseSmartData(t *testing.T) {
+	data := make([]byte, 512)
+	smartEntry(data, 0, AttrReallocated, 0x33, 100, 100, 8)
+	smartEntry(data, 1, AttrTemperature, 0x22, 64, 40, 0x2d0012_0024)
+	smartEntry(data, 3, 250, 0x00, 200, 200, 1<<40) // slot 2 is empty
+	thresholds := make([]byte, 512)
+	thresholds[2], thresholds[3] = AttrReallocated, 36
+
+	attrs := parseSmartData(data, thresholds)
+	want := []SmartAttr{
+		{ID: 5, Name: "Reallocated_Sector_Ct", Value: 100, Worst: 100, Threshold: 36, Raw: 8, PreFail: true},
+		{ID: 194, Name: "Temperature_Celsius", Value: 64, Worst: 40, Raw: 0x2d0012_0024},
+		{ID: 250, Name: "Unknown_Attribute_250", Value: 200, Worst: 200, Raw: 1 << 40},
+	}
+	if len(attrs) != len(want) {
+		t.Fatalf("parseSmartData = %+v, want %+v", attrs, want)
+	}
+	for i := range want {
+		if attrs[i] != want[i] {
+			t.Errorf("attr %d = %+v, want %+v", i, attrs[i], want[i])
+		}
+	}
+
+	d := &SmartDrive{Attrs: attrs}
+	if temp, ok := d.Temperature(); !ok || temp != 36 {
+		t.Errorf("Temperature() = %d, %v; want 36", temp, ok)
+	}
+}
+
+func TestParseSmartDataShort(t *testing.T) {
+	data := make([]byte, 2+12+5) // one whole entry and a truncated one
+	smartEntry(data, 0, AttrPowerOnHours, 0x32, 99, 99, 1234)
+	attrs := parseSmartData(data, nil)
+	if len(attrs) != 1 || attrs[0].Raw != 1234 || attrs[0].Threshold != 0 {
+		t.Errorf("parseSmartData = %+v, want only Power_On_Hours", attrs)
+	}
+}
+
+func TestATAString(t *testing.T) {
+	identify := make([]byte, 512)
+	// Words 10-19 hold the serial, two characters per word, bytes swapped
+	serial := "  WD-WX12345678"
+	for len(serial) < 20 {
+		serial += " "
+	}
+	for i := 0; i < 20; i += 2 {
+		identify[20+i], identify[20+i+1] = serial[i+1], serial[i]
+	}
+	if got := ataString(identify, 10, 10); got != "WD-WX12345678" {
+		t.Errorf("ataString = %q, want WD-WX12345678", got)
+	}
+	if got := ataString(identify[:24], 10, 10); got != "WD" {
+		t.Errorf("ataString of a short buffer = %q, want WD", got)
+	}
+}
+
+func TestSmartBaseline(t *testing.T) {
+	if smartBaseline(nil) != nil {
+		t.Error("smartBaseline(nil) is not nil")
+	}
+	snaps := []SmartSnapshot{
+		{Verdict: "first"},
+		{Verdict: "acked", Baseline: true},
+		{Verdict: "later"},
+		{Verdict: "acked again", Baseline: true},
+		{Verdict: "latest"},
+	}
+	if got := smartBaseline(snaps).Verdict; got != "acked again" {
+		t.Errorf("smartBaseline = %q, want the last acknowledged", got)
+	}
+	if got := smartBaseline(snaps[2:3]).Verdict; got != "later" {
+		t.Errorf("smartBaseline without ack = %q, want the first", got)
+	}
+}
+
+func TestAssess(t *testing.T) {
+	failed := false
+	base := &SmartSnapshot{Time: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), Raw: map[int]int64{AttrCRCErrors: 2}}
+	tests := []struct {
+		name    string
+		drive   SmartDrive
+		base    *SmartSnapshot
+		verdict string
+		reason  string
+	}{
+		{"healthy", SmartDrive{Attrs: []SmartAttr{{ID: AttrCRCErrors, Raw: 2}}}, base, SmartOK, ""},
+		{"status failed", SmartDrive{Passed: &failed}, nil, SmartFailing, "SMART status FAILED"},
+		{"prefail at threshold", SmartDrive{Attrs: []SmartAttr{
+			{ID: 1, Name: "Raw_Read_Error_Rate", Value: 6, Threshold: 6, PreFail: true},
+		}}, nil, SmartFailing, "Raw_Read_Error_Rate at 6, threshold 6"},
+		{"old-age at threshold", SmartDrive{Attrs: []SmartAttr{
+			{ID: 1, Value: 6, Threshold: 6},
+		}}, nil, SmartOK, ""},
+		{"pending sectors", SmartDrive{Attrs: []SmartAttr{
+			{ID: AttrPending, Name: "Current_Pending_Sector", Raw: 3},
+		}}, nil, SmartWarning, "Current_Pending_Sector = 3"},
+		{"crc rose since baseline", SmartDrive{Attrs: []SmartAttr{
+			{ID: AttrCRCErrors, Name: "UDMA_CRC_Error_Count", Raw: 5},
+		}}, base, SmartWarning, "rose from 2 to 5 since Mar 01"},
+		{"hot", SmartDrive{Attrs: []SmartAttr{{ID: AttrTemperature, Raw: 58}}}, nil, SmartWarning, "running hot at 58°C"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := tt.drive
+			assess(&d, tt.base)
+			if d.Verdict != tt.verdict {
+				t.Errorf("verdict = %s, want %s (reasons %v)", d.Verdict, tt.verdict, d.Reasons)
+			}
+			if reasons := strings.Join(d.Reasons, "; "); !strings.Contains(reasons, tt.reason) || tt.reason == "" && reasons != "" {
+				t.Errorf("reasons %q do not mention %q", reasons, tt.reason)
+			}
+		})
+	}
+}
+
+func TestAcknowledgeSmart(t *testing.T) {
+	dir := t.TempDir()
+	d := &SmartDrive{Serial: "WD 123/4", Verdict: SmartWarning, Attrs: []SmartAttr{{ID: AttrCRCErrors, Raw: 5}}}
+	if err := RecordSmart(dir, d); err != nil {
+		t.Fatal(err)
+	}
+	if err := AcknowledgeSmart(dir, d); err != nil {
+		t.Fatal(err)
+	}
+	d.Attrs[0].Raw = 9
+	if err := RecordSmart(dir, d); err != nil {
+		t.Fatal(err)
+	}
+
+	snaps, err := SmartHistory(dir, d.Serial)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snaps) != 3 {
+		t.Fatalf("got %d snapshots, want 3", len(snaps))
+	}
+	// Later readings do not move the baseline, so the rise to 9 still shows
+	if base := smartBaseline(snaps); !base.Baseline || base.Raw[AttrCRCErrors] != 5 {
+		t.Errorf("baseline = %+v, want the acknowledged reading of 5", base)
+	}
+}
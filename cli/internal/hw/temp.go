@@ -1,49 +1,39 @@
 package hw
 
 import (
+	"context"
+	"fmt"
 	"os"
 	"strconv"
 	"strings"
 
 	"github.com/anibalnet/blackbeard/cli/internal/ui"
 	"github.com/fatih/color"
-	"github.com/shirou/gopsutil/v4/sensors"
 )
 
 // TempReading holds a temperature reading.
 type TempReading struct {
-	Label   string
-	Celsius float64
-	Valid   bool
+	Label   string  `json:"label"`
+	Celsius float64 `json:"celsius"`
+	Valid   bool    `json:"valid"`
 }
 
-// ReadTemps reads all temperature sensors via gopsutil.
+// ReadTemps returns one CPU and one GPU reading, taken from the first
+// sensor of each group.
 func ReadTemps() map[string]TempReading {
 	result := make(map[string]TempReading)
 
-	temps, err := sensors.SensorsTemperatures()
-	if err != nil {
-		return result
-	}
-
-	for _, t := range temps {
-		if t.Temperature <= 0 {
-			continue
-		}
-		label := classifyTempSensor(t.SensorKey)
-		if label == "" {
+	for _, s := range ReadSensors(nil) {
+		label := strings.ToUpper(s.Group)
+		if label != "CPU" && label != "GPU" {
 			continue
 		}
 		if _, exists := result[label]; !exists {
-			result[label] = TempReading{
-				Label:   label,
-				Celsius: t.Temperature,
-				Valid:   true,
-			}
+			result[label] = TempReading{Label: label, Celsius: s.Celsius, Valid: true}
 		}
 	}
 
-	// Fallback: try direct GPU temperature read if gopsutil didn't find it
+	// Fallback: read the RK3566 GPU zone directly if it was not discovered
 	if _, hasGPU := result["GPU"]; !hasGPU {
 		gpuTemp := ReadGPUTempDirect()
 		if gpuTemp.Valid {
@@ -54,22 +44,8 @@ func ReadTemps() map[string]TempReading {
 	return result
 }
 
-// classifyTempSensor maps gopsutil sensor keys to labels.
-// On RK3566 thermal zones are typically named with cpu/soc/gpu prefixes.
-func classifyTempSensor(key string) string {
-	lower := strings.ToLower(key)
-	switch {
-	case strings.Contains(lower, "cpu") || strings.Contains(lower, "soc"):
-		return "CPU"
-	case strings.Contains(lower, "gpu"):
-		return "GPU"
-	default:
-		return ""
-	}
-}
-
 // ReadGPUTempDirect reads GPU temperature directly from thermal zone.
-// This is a fallback when gopsutil doesn't detect the GPU thermal sensor.
+// This is a fallback when the GPU zone has an unrecognised type.
 func ReadGPUTempDirect() TempReading {
 	// thermal_zone1 is confirmed as gpu-thermal on RK3566
 	data, err := os.ReadFile("/sys/class/thermal/thermal_zone1/temp")
@@ -114,8 +90,23 @@ func getTempColor(celsius float64) *color.Color {
 	}
 }
 
-// RunTemp shows temperature for the specified target.
-func RunTemp(p *ui.Printer, target string) error {
+// RunTemp shows temperature for the specified target. With a sensor name
+// only that sensor is shown; "all" lists every sensor by group.
+func RunTemp(p *ui.Printer, target, sensor string, cfg *SensorConfig) error {
+	if sensor != "" {
+		sensors := ReadSensors(cfg)
+		s, ok := FindSensor(sensors, sensor)
+		if !ok {
+			names := make([]string, 0, len(sensors))
+			for _, s := range sensors {
+				names = append(names, s.Name)
+			}
+			return fmt.Errorf("unknown sensor %q (available: %s)", sensor, strings.Join(names, ", "))
+		}
+		p.Printf("%s: %s\n", s.Label, FormatTemp(s.Reading()))
+		return nil
+	}
+
 	temps := ReadTemps()
 
 	get := func(label string) TempReading {
@@ -132,17 +123,32 @@ func RunTemp(p *ui.Printer, target string) error {
 		p.Printf("GPU: %s\n", FormatTemp(get("GPU")))
 	default:
 		p.Printf("CPU: %s | GPU: %s\n", FormatTemp(get("CPU")), FormatTemp(get("GPU")))
+
+		sensors := ReadSensors(cfg)
+		if len(sensors) > 0 {
+			p.Println("")
+			table := ui.NewTable(p.Out, "GROUP", "SENSOR", "LABEL", "TEMP")
+			for _, s := range sensors {
+				label := s.Label
+				if label == s.Name {
+					label = ""
+				}
+				table.Row(s.Group, s.Name, label, FormatTemp(s.Reading()))
+			}
+			table.Flush()
+		}
 	}
 	return nil
 }
 
 // RunFullStatus shows comprehensive hardware status.
-func RunFullStatus(p *ui.Printer) error {
+func RunFullStatus(p *ui.Printer, cfg *SensorConfig, stateDir string, storage []StoragePath) error {
 	RunInfo(p)
 	p.Println("")
 
 	p.Header("Temperature")
-	RunTemp(p, "all")
+	RunTemp(p, "all", "", cfg)
+	p.Printf("Throttled: %s\n", FormatThrottle(ReadThrottle()))
 	p.Println("")
 
 	RunCPU(p)
@@ -151,11 +157,19 @@ func RunFullStatus(p *ui.Printer) error {
 	RunMem(p)
 	p.Println("")
 
-	RunDisk(p, nil)
+	RunDisk(p, nil, storage)
+	for _, device := range SmartDevices() {
+		d := ReadSmart(device, stateDir)
+		line := fmt.Sprintf("SMART %s %s: %s", d.Device, d.Model, FormatVerdict(d.Verdict))
+		if len(d.Reasons) > 0 {
+			line += " (" + strings.Join(d.Reasons, "; ") + ")"
+		}
+		p.Println(line)
+	}
 	p.Println("")
 
-	RunNet(p)
+	RunNet(context.Background(), p, 1, false, nil)
 	p.Println("")
 
-	return RunGPUStatus(p)
+	return RunGPUStatus(p, nil)
 }
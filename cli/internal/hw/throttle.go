@@ -0,0 +1,226 @@
+package hw
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+const (
+	CPUFreqPath = "/sys/devices/system/cpu/cpufreq"
+	ThermalPath = "/sys/class/thermal"
+)
+
+// CPUPolicy is one cpufreq policy, i.e. a cluster of cores sharing a clock.
+// Frequencies are in Hz.
+type CPUPolicy struct {
+	Name      string `json:"name"`
+	CPUs      []int  `json:"cpus"`
+	Governor  string `json:"governor"`
+	CurFreq   int64  `json:"cur_freq"`
+	MinFreq   int64  `json:"min_freq"`
+	MaxFreq   int64  `json:"max_freq"`
+	HWMinFreq int64  `json:"hw_min_freq"`
+	HWMaxFreq int64  `json:"hw_max_freq"`
+}
+
+// Capped reports whether scaling_max_freq is below the hardware maximum.
+func (p CPUPolicy) Capped() bool {
+	return p.HWMaxFreq > 0 && p.MaxFreq > 0 && p.MaxFreq < p.HWMaxFreq
+}
+
+// TripPoint is a thermal zone temperature at which cooling kicks in.
+type TripPoint struct {
+	Type    string  `json:"type"`
+	Celsius float64 `json:"celsius"`
+}
+
+// ThermalZone is a kernel thermal zone with its trip points.
+type ThermalZone struct {
+	Name    string      `json:"name"`
+	Type    string      `json:"type"`
+	Celsius float64     `json:"celsius"`
+	Trips   []TripPoint `json:"trips"`
+}
+
+// TrippedPassive returns the lowest passive trip the zone has reached.
+func (z ThermalZone) TrippedPassive() (TripPoint, bool) {
+	for _, t := range z.Trips {
+		if t.Type == "passive" && z.Celsius >= t.Celsius {
+			return t, true
+		}
+	}
+	return TripPoint{}, false
+}
+
+// CoolingDevice is a thermal cooling device: a cpufreq or devfreq limiter,
+// or a fan. A current state above zero means it is active.
+type CoolingDevice struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	CurState int    `json:"cur_state"`
+	MaxState int    `json:"max_state"`
+}
+
+// Limiter reports whether the device cools by lowering clocks, so being
+// active means throttling. Fans and other devices only add cooling.
+func (c CoolingDevice) Limiter() bool {
+	for _, prefix := range []string{"cpufreq-", "devfreq-", "thermal-cpufreq-", "thermal-devfreq-"} {
+		if strings.HasPrefix(c.Type, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Throttle summarises CPU clocks and thermal limiting.
+type Throttle struct {
+	Throttled bool            `json:"throttled"`
+	Reasons   []string        `json:"reasons,omitempty"`
+	Policies  []CPUPolicy     `json:"policies"`
+	Zones     []ThermalZone   `json:"zones"`
+	Cooling   []CoolingDevice `json:"cooling"`
+}
+
+func readSysString(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+func readSysInt(path string) int64 {
+	n, _ := strconv.ParseInt(readSysString(path), 10, 64)
+	return n
+}
+
+// sortedGlob returns matches ordered by their numeric suffix, so zone10
+// sorts after zone2.
+func sortedGlob(pattern, prefix string) []string {
+	paths, _ := filepath.Glob(pattern)
+	num := func(p string) int {
+		n, _ := strconv.Atoi(strings.TrimPrefix(filepath.Base(p), prefix))
+		return n
+	}
+	sort.Slice(paths, func(i, j int) bool { return num(paths[i]) < num(paths[j]) })
+	return paths
+}
+
+// ReadCPUPolicies reads every cpufreq policy. Sysfs reports kHz.
+func ReadCPUPolicies() []CPUPolicy {
+	var policies []CPUPolicy
+	for _, dir := range sortedGlob(filepath.Join(CPUFreqPath, "policy*"), "policy") {
+		p := CPUPolicy{
+			Name:      filepath.Base(dir),
+			Governor:  readSysString(filepath.Join(dir, "scaling_governor")),
+			CurFreq:   readSysInt(filepath.Join(dir, "scaling_cur_freq")) * 1000,
+			MinFreq:   readSysInt(filepath.Join(dir, "scaling_min_freq")) * 1000,
+			MaxFreq:   readSysInt(filepath.Join(dir, "scaling_max_freq")) * 1000,
+			HWMinFreq: readSysInt(filepath.Join(dir, "cpuinfo_min_freq")) * 1000,
+			HWMaxFreq: readSysInt(filepath.Join(dir, "cpuinfo_max_freq")) * 1000,
+		}
+		for _, f := range strings.Fields(readSysString(filepath.Join(dir, "related_cpus"))) {
+			if n, err := strconv.Atoi(f); err == nil {
+				p.CPUs = append(p.CPUs, n)
+			}
+		}
+		policies = append(policies, p)
+	}
+	return policies
+}
+
+// ReadCoreFreqs returns the current frequency of each core in Hz, indexed by
+// CPU number. Cores without cpufreq report 0.
+func ReadCoreFreqs(cores int) []int64 {
+	freqs := make([]int64, cores)
+	for i := range freqs {
+		freqs[i] = readSysInt(fmt.Sprintf("/sys/devices/system/cpu/cpu%d/cpufreq/scaling_cur_freq", i)) * 1000
+	}
+	return freqs
+}
+
+// ReadThermalZones reads every thermal zone with its trip points.
+func ReadThermalZones() []ThermalZone {
+	var zones []ThermalZone
+	for _, dir := range sortedGlob(filepath.Join(ThermalPath, "thermal_zone*"), "thermal_zone") {
+		z := ThermalZone{
+			Name:    filepath.Base(dir),
+			Type:    readSysString(filepath.Join(dir, "type")),
+			Celsius: float64(readSysInt(filepath.Join(dir, "temp"))) / 1000,
+		}
+		for i := 0; ; i++ {
+			typ := readSysString(filepath.Join(dir, fmt.Sprintf("trip_point_%d_type", i)))
+			if typ == "" {
+				break
+			}
+			temp := readSysInt(filepath.Join(dir, fmt.Sprintf("trip_point_%d_temp", i)))
+			z.Trips = append(z.Trips, TripPoint{Type: typ, Celsius: float64(temp) / 1000})
+		}
+		sort.Slice(z.Trips, func(i, j int) bool { return z.Trips[i].Celsius < z.Trips[j].Celsius })
+		zones = append(zones, z)
+	}
+	return zones
+}
+
+// ReadCoolingDevices reads every thermal cooling device.
+func ReadCoolingDevices() []CoolingDevice {
+	var devices []CoolingDevice
+	for _, dir := range sortedGlob(filepath.Join(ThermalPath, "cooling_device*"), "cooling_device") {
+		devices = append(devices, CoolingDevice{
+			Name:     filepath.Base(dir),
+			Type:     readSysString(filepath.Join(dir, "type")),
+			CurState: int(readSysInt(filepath.Join(dir, "cur_state"))),
+			MaxState: int(readSysInt(filepath.Join(dir, "max_state"))),
+		})
+	}
+	return devices
+}
+
+// ReadThrottle reads clocks and thermal state and decides whether the board
+// is being thermally throttled: a clock limiting cooling device is active or
+// a zone has reached a passive trip point. A lowered scaling_max_freq on its own is
+// reported but may be a manual limit.
+func ReadThrottle() Throttle {
+	t := Throttle{
+		Policies: ReadCPUPolicies(),
+		Zones:    ReadThermalZones(),
+		Cooling:  ReadCoolingDevices(),
+	}
+
+	for _, c := range t.Cooling {
+		if c.Limiter() && c.CurState > 0 {
+			t.Throttled = true
+			t.Reasons = append(t.Reasons, fmt.Sprintf("%s active (state %d/%d)", c.Type, c.CurState, c.MaxState))
+		}
+	}
+	for _, z := range t.Zones {
+		if trip, ok := z.TrippedPassive(); ok {
+			t.Throttled = true
+			t.Reasons = append(t.Reasons, fmt.Sprintf("%s at %.1f°C passed passive trip %.0f°C", z.Type, z.Celsius, trip.Celsius))
+		}
+	}
+	for _, p := range t.Policies {
+		if p.Capped() {
+			reason := fmt.Sprintf("%s capped at %d of %d MHz", p.Name, p.MaxFreq/1000000, p.HWMaxFreq/1000000)
+			if !t.Throttled {
+				reason += " (not thermal: scaling_max_freq set manually?)"
+			}
+			t.Reasons = append(t.Reasons, reason)
+		}
+	}
+	return t
+}
+
+// FormatThrottle returns a color-coded one-word throttling status.
+func FormatThrottle(t Throttle) string {
+	if t.Throttled {
+		return color.New(color.FgRed, color.Bold).Sprint("THROTTLED")
+	}
+	return color.New(color.FgGreen).Sprint("no")
+}
@@ -0,0 +1,23 @@
+package hw
+
+import "testing"
+
+func TestCoolingDeviceLimiter(t *testing.T) {
+	tests := []struct {
+		typ  string
+		want bool
+	}{
+		{"cpufreq-cpu0", true},
+		{"devfreq-fb000000.gpu", true},
+		{"thermal-cpufreq-0", true},
+		{"thermal-devfreq-0", true},
+		{"pwm-fan", false},
+		{"gpio-fan", false},
+		{"Processor", false},
+	}
+	for _, tt := range tests {
+		if got := (CoolingDevice{Type: tt.typ}).Limiter(); got != tt.want {
+			t.Errorf("Limiter(%q) = %v, want %v", tt.typ, got, tt.want)
+		}
+	}
+}
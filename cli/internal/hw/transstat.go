@@ -0,0 +1,180 @@
+package hw
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// FreqState is the time spent at one devfreq frequency and how often the
+// device switched to it.
+type FreqState struct {
+	Freq    int64         `json:"freq"`
+	Time    time.Duration `json:"time_ns"`
+	Entered int64         `json:"entered"`
+	Current bool          `json:"current"`
+}
+
+// FreqResidency is the parsed devfreq trans_stat table.
+type FreqResidency struct {
+	States      []FreqState `json:"states"`
+	Transitions int64       `json:"transitions"`
+}
+
+// ParseTransStat parses a devfreq trans_stat file:
+//
+//	     From  :   To
+//	           : 200000000 300000000   time(ms)
+//	* 200000000:         0         4      1200
+//	  300000000:         3         0       300
+//	Total transition : 7
+func ParseTransStat(raw string) (*FreqResidency, error) {
+	r := &FreqResidency{}
+	var entered []int64
+
+	for _, line := range strings.Split(raw, "\n") {
+		head, rest, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		head = strings.TrimSpace(head)
+		fields := strings.Fields(rest)
+
+		switch {
+		case head == "From" || head == "":
+			continue
+		case strings.HasPrefix(head, "Total transition"):
+			if len(fields) > 0 {
+				r.Transitions, _ = strconv.ParseInt(fields[0], 10, 64)
+			}
+			continue
+		}
+
+		state := FreqState{Current: strings.HasPrefix(head, "*")}
+		freq, err := strconv.ParseInt(strings.TrimSpace(strings.TrimPrefix(head, "*")), 10, 64)
+		if err != nil || len(fields) == 0 {
+			return nil, fmt.Errorf("unexpected trans_stat row %q", line)
+		}
+		state.Freq = freq
+
+		counts := fields[:len(fields)-1]
+		if entered == nil {
+			entered = make([]int64, len(counts))
+		}
+		for j, c := range counts {
+			n, _ := strconv.ParseInt(c, 10, 64)
+			if j < len(entered) {
+				entered[j] += n
+			}
+		}
+		ms, _ := strconv.ParseInt(fields[len(fields)-1], 10, 64)
+		state.Time = time.Duration(ms) * time.Millisecond
+		r.States = append(r.States, state)
+	}
+
+	if len(r.States) == 0 {
+		return nil, fmt.Errorf("no frequencies in trans_stat")
+	}
+	for i := range r.States {
+		if i < len(entered) {
+			r.States[i].Entered = entered[i]
+		}
+	}
+	return r, nil
+}
+
+// Total returns the time covered by the table.
+func (r *FreqResidency) Total() time.Duration {
+	var total time.Duration
+	for _, s := range r.States {
+		total += s.Time
+	}
+	return total
+}
+
+// Top returns the highest frequency in the table.
+func (r *FreqResidency) Top() int64 {
+	var top int64
+	for _, s := range r.States {
+		top = max(top, s.Freq)
+	}
+	return top
+}
+
+// Share returns the fraction of time spent at freq.
+func (r *FreqResidency) Share(freq int64) float64 {
+	total := r.Total()
+	if total == 0 {
+		return 0
+	}
+	for _, s := range r.States {
+		if s.Freq == freq {
+			return float64(s.Time) / float64(total)
+		}
+	}
+	return 0
+}
+
+// Delta returns the residency accumulated since prev. Writing to trans_stat
+// resets its counters, so when any counter went backwards the counts since
+// the reset are returned instead.
+func (r *FreqResidency) Delta(prev *FreqResidency) *FreqResidency {
+	if prev == nil || r.Transitions < prev.Transitions {
+		return r
+	}
+	before := map[int64]FreqState{}
+	for _, s := range prev.States {
+		before[s.Freq] = s
+	}
+	d := &FreqResidency{Transitions: r.Transitions - prev.Transitions}
+	for _, s := range r.States {
+		p := before[s.Freq]
+		if s.Time < p.Time || s.Entered < p.Entered {
+			return r
+		}
+		s.Time -= p.Time
+		s.Entered -= p.Entered
+		d.States = append(d.States, s)
+	}
+	return d
+}
+
+// residencyLines renders a time-in-state histogram, one line per frequency,
+// with bars scaled to barWidth.
+func residencyLines(r *FreqResidency, barWidth int) []string {
+	total := r.Total()
+	maxFreq := r.Top()
+
+	lines := make([]string, 0, len(r.States))
+	for _, s := range r.States {
+		share := 0.0
+		if total > 0 {
+			share = float64(s.Time) / float64(total)
+		}
+		filled := min(max(int(share*float64(barWidth)+0.5), 0), barWidth)
+		pct := 0
+		if maxFreq > 0 {
+			pct = int(s.Freq * 100 / maxFreq)
+		}
+		bar := getFreqColor(pct).Sprint(strings.Repeat("█", filled)) +
+			color.New(color.FgHiBlack).Sprint(strings.Repeat("░", barWidth-filled))
+
+		marker := " "
+		if s.Current {
+			marker = "*"
+		}
+		lines = append(lines, fmt.Sprintf("%s%4d MHz %s %5.1f%% %9s %6d×",
+			marker, s.Freq/1000000, bar, share*100, formatResidency(s.Time), s.Entered))
+	}
+	return lines
+}
+
+func formatResidency(d time.Duration) string {
+	if d >= time.Hour {
+		return formatUptime(uint64(d.Seconds()))
+	}
+	return d.Round(100 * time.Millisecond).String()
+}
@@ -0,0 +1,143 @@
+package hw
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+const transStat = `     From  :   To
+           : 200000000 300000000 400000000   time(ms)
+* 200000000:         0         4         1      1200
+  300000000:         3         0         2       300
+  400000000:         1         1         0       500
+Total transition : 12
+`
+
+func TestParseTransStat(t *testing.T) {
+	r, err := ParseTransStat(transStat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []FreqState{
+		{Freq: 200000000, Time: 1200 * time.Millisecond, Entered: 4, Current: true},
+		{Freq: 300000000, Time: 300 * time.Millisecond, Entered: 5},
+		{Freq: 400000000, Time: 500 * time.Millisecond, Entered: 3},
+	}
+	if len(r.States) != len(want) {
+		t.Fatalf("States = %+v, want %+v", r.States, want)
+	}
+	for i := range want {
+		if r.States[i] != want[i] {
+			t.Errorf("States[%d] = %+v, want %+v", i, r.States[i], want[i])
+		}
+	}
+	if r.Transitions != 12 {
+		t.Errorf("Transitions = %d, want 12", r.Transitions)
+	}
+	if r.Total() != 2*time.Second || r.Top() != 400000000 {
+		t.Errorf("Total, Top = %v, %d; want 2s, 400000000", r.Total(), r.Top())
+	}
+	if got := r.Share(200000000); got != 0.6 {
+		t.Errorf("Share(200MHz) = %v, want 0.6", got)
+	}
+}
+
+func TestParseTransStatInvalid(t *testing.T) {
+	for _, raw := range []string{
+		"",
+		"     From  :   To\nTotal transition : 0\n",
+		"  abc: 1 2 3\n",
+	} {
+		if _, err := ParseTransStat(raw); err == nil {
+			t.Errorf("ParseTransStat(%q) succeeded, want an error", raw)
+		}
+	}
+}
+
+func TestDelta(t *testing.T) {
+	prev := &FreqResidency{Transitions: 10, States: []FreqState{
+		{Freq: 200, Time: time.Second, Entered: 5},
+		{Freq: 400, Time: 2 * time.Second, Entered: 5},
+	}}
+	cur := &FreqResidency{Transitions: 14, States: []FreqState{
+		{Freq: 200, Time: 3 * time.Second, Entered: 7, Current: true},
+		{Freq: 400, Time: 2 * time.Second, Entered: 7},
+	}}
+
+	d := cur.Delta(prev)
+	if d.Transitions != 4 {
+		t.Errorf("Transitions = %d, want 4", d.Transitions)
+	}
+	want := []FreqState{
+		{Freq: 200, Time: 2 * time.Second, Entered: 2, Current: true},
+		{Freq: 400, Time: 0, Entered: 2},
+	}
+	for i := range want {
+		if d.States[i] != want[i] {
+			t.Errorf("States[%d] = %+v, want %+v", i, d.States[i], want[i])
+		}
+	}
+
+	if got := cur.Delta(nil); got != cur {
+		t.Error("Delta(nil) did not return the reading itself")
+	}
+}
+
+func TestDeltaAfterReset(t *testing.T) {
+	prev := &FreqResidency{Transitions: 10, States: []FreqState{
+		{Freq: 200, Time: 5 * time.Second, Entered: 5},
+		{Freq: 400, Time: 5 * time.Second, Entered: 5},
+	}}
+	tests := []struct {
+		name string
+		cur  *FreqResidency
+	}{
+		{"transitions", &FreqResidency{Transitions: 2, States: []FreqState{
+			{Freq: 200, Time: 6 * time.Second, Entered: 6},
+			{Freq: 400, Time: 6 * time.Second, Entered: 6},
+		}}},
+		// Transitions alone can climb past the old total before the next read
+		{"time", &FreqResidency{Transitions: 12, States: []FreqState{
+			{Freq: 200, Time: 6 * time.Second, Entered: 6},
+			{Freq: 400, Time: time.Second, Entered: 6},
+		}}},
+		{"entered", &FreqResidency{Transitions: 12, States: []FreqState{
+			{Freq: 200, Time: 6 * time.Second, Entered: 1},
+			{Freq: 400, Time: 6 * time.Second, Entered: 6},
+		}}},
+	}
+	for _, tt := range tests {
+		if got := tt.cur.Delta(prev); got != tt.cur {
+			t.Errorf("%s went backwards: Delta = %+v, want the reading itself", tt.name, got)
+		}
+	}
+}
+
+func TestResidencyLines(t *testing.T) {
+	color.NoColor = true
+	r := &FreqResidency{States: []FreqState{
+		{Freq: 200000000, Time: 3 * time.Second},
+		{Freq: 400000000, Time: time.Second, Current: true},
+		// A stale reading can hold a negative time; it must not break the bar
+		{Freq: 600000000, Time: -time.Second},
+	}}
+
+	lines := residencyLines(r, 10)
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3", len(lines))
+	}
+	for i, wantFilled := range []int{10, 3, 0} {
+		if got := strings.Count(lines[i], "█"); got != wantFilled {
+			t.Errorf("line %d has %d filled cells, want %d: %q", i, got, wantFilled, lines[i])
+		}
+		if got := strings.Count(lines[i], "█") + strings.Count(lines[i], "░"); got != 10 {
+			t.Errorf("line %d bar is %d wide, want 10", i, got)
+		}
+	}
+	if !strings.HasPrefix(lines[1], "*") {
+		t.Errorf("current frequency not marked: %q", lines[1])
+	}
+}
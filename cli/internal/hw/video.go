@@ -0,0 +1,103 @@
+package hw
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Sysfs roots of V4L2 video nodes and media controller devices.
+const (
+	V4L2Path  = "/sys/class/video4linux"
+	MediaPath = "/sys/bus/media/devices"
+)
+
+// Roles of the video nodes used for transcoding.
+const (
+	VideoRGA     = "rga"
+	VideoDecoder = "decoder"
+	VideoEncoder = "encoder"
+	VideoOther   = "other"
+)
+
+// VideoDevice is a /dev/videoN or /dev/mediaN node and what drives it.
+type VideoDevice struct {
+	Path   string `json:"path"`
+	Name   string `json:"name"` // sysfs name or media model
+	Role   string `json:"role"`
+	parent string
+}
+
+// VPU reports whether the node belongs to the RGA or the VPU.
+func (d VideoDevice) VPU() bool { return d.Role != VideoOther }
+
+// videoRole classifies a V4L2 device by its driver-given name, e.g.
+// "rockchip-rga", "rockchip,rk3568-vpu-dec" or "rockchip,rk3568-vepu-enc".
+func videoRole(name string) string {
+	n := strings.ToLower(name)
+	switch {
+	case strings.Contains(n, "rga"):
+		return VideoRGA
+	case strings.HasSuffix(n, "-dec") || strings.Contains(n, "decoder") || strings.Contains(n, "vdec"):
+		return VideoDecoder
+	case strings.HasSuffix(n, "-enc") || strings.Contains(n, "encoder") || strings.Contains(n, "venc"):
+		return VideoEncoder
+	default:
+		return VideoOther
+	}
+}
+
+// ReadVideoDevices returns the video nodes, classified by their sysfs name,
+// followed by the media nodes, which take the role of the video node on the
+// same hardware block.
+func ReadVideoDevices() []VideoDevice {
+	var devices []VideoDevice
+	roles := map[string]string{}
+	for _, dir := range sortedGlob(filepath.Join(V4L2Path, "video*"), "video") {
+		name := readSysString(filepath.Join(dir, "name"))
+		d := VideoDevice{
+			Path:   "/dev/" + filepath.Base(dir),
+			Name:   name,
+			Role:   videoRole(name),
+			parent: sysParent(dir),
+		}
+		if d.parent != "" && d.Role != VideoOther {
+			roles[d.parent] = d.Role
+		}
+		devices = append(devices, d)
+	}
+
+	for _, dir := range sortedGlob(filepath.Join(MediaPath, "media*"), "media") {
+		d := VideoDevice{
+			Path:   "/dev/" + filepath.Base(dir),
+			Name:   readSysString(filepath.Join(dir, "model")),
+			Role:   VideoOther,
+			parent: sysParent(dir),
+		}
+		if role, ok := roles[d.parent]; ok {
+			d.Role = role
+		}
+		devices = append(devices, d)
+	}
+	return devices
+}
+
+// sysParent resolves the device a sysfs class entry belongs to.
+func sysParent(dir string) string {
+	parent, err := filepath.EvalSymlinks(filepath.Join(dir, "device"))
+	if err != nil {
+		return ""
+	}
+	return parent
+}
+
+// VPUDevices returns the RGA and VPU nodes, video and media, that exist.
+func VPUDevices() []VideoDevice {
+	var out []VideoDevice
+	for _, d := range ReadVideoDevices() {
+		if _, err := os.Stat(d.Path); err == nil && d.VPU() {
+			out = append(out, d)
+		}
+	}
+	return out
+}
@@ -0,0 +1,154 @@
+package metrics
+
+import (
+	"encoding/csv"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/anibalnet/blackbeard/cli/internal/config"
+	"github.com/anibalnet/blackbeard/cli/internal/ui"
+)
+
+// match returns the recorded metrics selected by name: an exact name, a
+// group prefix such as "temp", or every metric when name is empty.
+func match(names []string, name string) []string {
+	if name == "" || slices.Contains(names, name) {
+		if name != "" {
+			return []string{name}
+		}
+		return names
+	}
+	var matched []string
+	for _, n := range names {
+		if strings.HasPrefix(n, name+".") {
+			matched = append(matched, n)
+		}
+	}
+	return matched
+}
+
+// Summarize returns the minimum, average and maximum over points.
+func Summarize(points []Point) (lo, avg, hi float64) {
+	lo, hi = math.Inf(1), math.Inf(-1)
+	var sum float64
+	var count int
+	for _, p := range points {
+		lo = math.Min(lo, p.Min)
+		hi = math.Max(hi, p.Max)
+		sum += p.Avg * float64(p.Count)
+		count += p.Count
+	}
+	if count > 0 {
+		avg = sum / float64(count)
+	}
+	return lo, avg, hi
+}
+
+// columns spreads points over width columns keeping the maximum of each, so
+// short spikes stay visible. Columns without data are NaN.
+func columns(points []Point, since, until time.Time, width int) []float64 {
+	if width < 1 {
+		return nil
+	}
+	cols := make([]float64, width)
+	for i := range cols {
+		cols[i] = math.NaN()
+	}
+	span := until.Sub(since)
+	if span <= 0 {
+		return cols
+	}
+	for _, p := range points {
+		i := int(float64(p.At.Sub(since)) / float64(span) * float64(width))
+		i = max(0, min(i, width-1))
+		if math.IsNaN(cols[i]) || p.Max > cols[i] {
+			cols[i] = p.Max
+		}
+	}
+	return cols
+}
+
+// formatPeriod prints durations the way --since accepts them.
+func formatPeriod(d time.Duration) string {
+	if d >= 48*time.Hour && d%(24*time.Hour) == 0 {
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	}
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+func formatValue(v float64, unit string) string {
+	return strconv.FormatFloat(v, 'f', 1, 64) + unit
+}
+
+// RunHistory shows recorded history of a metric (or group) as min/avg/max
+// and a sparkline, or as CSV.
+func RunHistory(cfg *config.Config, p *ui.Printer, name string, since time.Duration, asCSV bool, width int) error {
+	store, err := Open(cfg.MetricsDir)
+	if err != nil {
+		return err
+	}
+	names, err := store.Names()
+	if err != nil || len(names) == 0 {
+		return fmt.Errorf("no metrics recorded in %s (history is recorded by: flint serve)", cfg.MetricsDir)
+	}
+	selected := match(names, name)
+	if len(selected) == 0 {
+		return fmt.Errorf("unknown metric %q (available: %s)", name, strings.Join(names, ", "))
+	}
+
+	until := time.Now()
+	from := until.Add(-since)
+
+	if asCSV {
+		w := csv.NewWriter(p.Out)
+		w.Write([]string{"time", "metric", "min", "avg", "max"})
+		for _, n := range selected {
+			points, _, err := store.Query(n, from, until)
+			if err != nil {
+				return err
+			}
+			for _, pt := range points {
+				w.Write([]string{
+					pt.At.UTC().Format(time.RFC3339), n,
+					strconv.FormatFloat(pt.Min, 'f', 2, 64),
+					strconv.FormatFloat(pt.Avg, 'f', 2, 64),
+					strconv.FormatFloat(pt.Max, 'f', 2, 64),
+				})
+			}
+		}
+		w.Flush()
+		return w.Error()
+	}
+
+	p.Header(fmt.Sprintf("Hardware History (last %s)", formatPeriod(since)))
+	table := ui.NewTable(p.Out, "METRIC", "MIN", "AVG", "MAX", "RESOLUTION", "HISTORY")
+	for _, n := range selected {
+		points, step, err := store.Query(n, from, until)
+		if err != nil {
+			return err
+		}
+		if len(points) == 0 {
+			table.Row(n, "-", "-", "-", formatPeriod(step), "no data in range")
+			continue
+		}
+		lo, avg, hi := Summarize(points)
+		unit := Unit(n)
+		table.Row(n, formatValue(lo, unit), formatValue(avg, unit), formatValue(hi, unit),
+			formatPeriod(step), ui.Sparkline(columns(points, from, until, width), lo, hi))
+	}
+	table.Flush()
+	p.Println("")
+	p.Info(fmt.Sprintf("%s → %s; the sparkline shows the peak of each column", from.Format("Jan 02 15:04"), until.Format("Jan 02 15:04")))
+	return nil
+}
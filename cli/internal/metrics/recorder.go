@@ -0,0 +1,101 @@
+package metrics
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/anibalnet/blackbeard/cli/internal/hw"
+)
+
+// Unit returns the display unit of a metric.
+func Unit(name string) string {
+	switch {
+	case name == "cpu", name == "mem", name == "throttled":
+		return "%"
+	case strings.HasPrefix(name, "temp."):
+		return "°C"
+	case name == "gpu.freq", name == "cpu.freq":
+		return "MHz"
+	case name == "vpu.irq":
+		return "irq/s"
+	}
+	return ""
+}
+
+// Recorder samples hardware readings into a Store.
+type Recorder struct {
+	store   *Store
+	prevVPU map[string]int64
+	prevAt  time.Time
+}
+
+// NewRecorder creates a recorder writing to store.
+func NewRecorder(store *Store) *Recorder {
+	return &Recorder{store: store}
+}
+
+// Sample reads the current value of every recorded metric.
+func (r *Recorder) Sample() map[string]float64 {
+	values := map[string]float64{}
+
+	// An interval of zero measures usage since the previous call, so the
+	// first reading only primes the counters.
+	if c, err := hw.ReadCPU(0); err == nil && !r.prevAt.IsZero() {
+		values["cpu"] = c.Percent
+	}
+	var freq int64
+	for _, pol := range hw.ReadCPUPolicies() {
+		freq = max(freq, pol.CurFreq)
+	}
+	if freq > 0 {
+		values["cpu.freq"] = float64(freq) / 1e6
+	}
+	// Recorded as 0 or 100 so the average is the share of time throttled
+	values["throttled"] = 0
+	if hw.ReadThrottle().Throttled {
+		values["throttled"] = 100
+	}
+	if m, err := hw.ReadMem(); err == nil {
+		values["mem"] = m.UsedPercent
+	}
+	for label, t := range hw.ReadTemps() {
+		values["temp."+strings.ToLower(label)] = t.Celsius
+	}
+	if gpu := hw.ReadGPUInfo(); gpu.MaxFreq > 0 {
+		values["gpu.freq"] = float64(gpu.CurrentFreq) / 1e6
+	}
+
+	now := time.Now()
+	current := hw.ReadVPUInterrupts()
+	if r.prevVPU != nil && len(current) > 0 {
+		var delta int64
+		for name, count := range current {
+			delta += count - r.prevVPU[name]
+		}
+		values["vpu.irq"] = float64(delta) / now.Sub(r.prevAt).Seconds()
+	}
+	r.prevVPU, r.prevAt = current, now
+	return values
+}
+
+// Run records a sample every Step until ctx is cancelled. Write errors are
+// passed to onErr and do not stop recording.
+func (r *Recorder) Run(ctx context.Context, onErr func(error)) {
+	ticker := time.NewTicker(Step)
+	defer ticker.Stop()
+	for {
+		at := time.Now()
+		for name, value := range r.Sample() {
+			if err := r.store.Add(name, at, value); err != nil {
+				onErr(err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
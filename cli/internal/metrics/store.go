@@ -0,0 +1,228 @@
+// Package metrics keeps a small on-disk history of hardware readings.
+//
+// Each metric is stored in fixed-size ring files, one per resolution, in the
+// style of RRD: slot i of a tier holds the bucket whose start time divided
+// by the tier step is i modulo the slot count. Samples are merged into every
+// tier as they arrive, so downsampling needs no separate pass.
+package metrics
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Step is how often the recorder samples, and the finest resolution kept.
+const Step = 10 * time.Second
+
+// tier is one resolution of the ring buffer.
+type tier struct {
+	name  string
+	step  time.Duration
+	slots int
+}
+
+// tiers are ordered finest first: one week at 10s, then 90 days at 5m.
+var tiers = []tier{
+	{name: "10s", step: Step, slots: 7 * 24 * 360},
+	{name: "5m", step: 5 * time.Minute, slots: 90 * 24 * 12},
+}
+
+func (t tier) retention() time.Duration {
+	return t.step * time.Duration(t.slots)
+}
+
+const (
+	magic      = "FLTM"
+	headerSize = 16
+	recordSize = 24 // start int64, count uint32, min, avg, max float32
+)
+
+// Point is the aggregate of the samples in one bucket.
+type Point struct {
+	At    time.Time `json:"at"`
+	Count int       `json:"count"`
+	Min   float64   `json:"min"`
+	Avg   float64   `json:"avg"`
+	Max   float64   `json:"max"`
+}
+
+// Store is a directory of metric ring files.
+type Store struct {
+	dir string
+}
+
+// Open returns the store in dir, creating the directory if needed.
+func Open(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating metrics directory: %w", err)
+	}
+	return &Store{dir: dir}, nil
+}
+
+func (s *Store) path(name string, t tier) string {
+	return filepath.Join(s.dir, name+"."+t.name+".ring")
+}
+
+// Add merges a sample into every tier of the named metric.
+func (s *Store) Add(name string, at time.Time, value float64) error {
+	for _, t := range tiers {
+		if err := s.add(name, t, at, value); err != nil {
+			return fmt.Errorf("recording %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func (s *Store) add(name string, t tier, at time.Time, value float64) error {
+	f, err := openRing(s.path(name, t), t, true)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	start := at.Truncate(t.step)
+	p, ok, err := readSlot(f, t, start)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		p = Point{At: start, Min: value, Max: value}
+	}
+	p.Avg = (p.Avg*float64(p.Count) + value) / float64(p.Count+1)
+	p.Count++
+	p.Min = math.Min(p.Min, value)
+	p.Max = math.Max(p.Max, value)
+	return writeSlot(f, t, p)
+}
+
+// Query returns the buckets of the named metric between since and until,
+// from the finest tier that still covers since. Empty buckets are skipped.
+func (s *Store) Query(name string, since, until time.Time) ([]Point, time.Duration, error) {
+	t := tiers[len(tiers)-1]
+	for _, candidate := range tiers {
+		if time.Since(since) <= candidate.retention() {
+			t = candidate
+			break
+		}
+	}
+
+	f, err := openRing(s.path(name, t), t, false)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, t.step, fmt.Errorf("no history for %s", name)
+		}
+		return nil, t.step, err
+	}
+	defer f.Close()
+
+	var points []Point
+	for at := since.Truncate(t.step); !at.After(until); at = at.Add(t.step) {
+		p, ok, err := readSlot(f, t, at)
+		if err != nil {
+			return nil, t.step, err
+		}
+		if ok {
+			points = append(points, p)
+		}
+	}
+	return points, t.step, nil
+}
+
+// Names lists the recorded metrics.
+func (s *Store) Names() ([]string, error) {
+	suffix := "." + tiers[0].name + ".ring"
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading metrics directory: %w", err)
+	}
+	var names []string
+	for _, e := range entries {
+		if name, ok := strings.CutSuffix(e.Name(), suffix); ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// openRing opens a ring file, creating a sparse one of the full size when
+// create is set. Files written with a different layout are rejected.
+func openRing(path string, t tier, create bool) (*os.File, error) {
+	flag := os.O_RDONLY
+	if create {
+		flag = os.O_RDWR | os.O_CREATE
+	}
+	f, err := os.OpenFile(path, flag, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, headerSize)
+	if _, err := f.ReadAt(header, 0); err == nil {
+		if string(header[:4]) != magic ||
+			binary.LittleEndian.Uint32(header[8:]) != uint32(t.step/time.Second) ||
+			binary.LittleEndian.Uint32(header[12:]) != uint32(t.slots) {
+			f.Close()
+			return nil, fmt.Errorf("%s: unexpected ring layout", path)
+		}
+		return f, nil
+	} else if !errors.Is(err, io.EOF) || !create {
+		f.Close()
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	copy(header, magic)
+	binary.LittleEndian.PutUint32(header[8:], uint32(t.step/time.Second))
+	binary.LittleEndian.PutUint32(header[12:], uint32(t.slots))
+	if err := f.Truncate(headerSize + int64(t.slots)*recordSize); err == nil {
+		_, err = f.WriteAt(header, 0)
+	}
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("creating %s: %w", path, err)
+	}
+	return f, nil
+}
+
+func slotOffset(t tier, start time.Time) int64 {
+	slot := (start.Unix() / int64(t.step/time.Second)) % int64(t.slots)
+	return headerSize + slot*recordSize
+}
+
+// readSlot returns the bucket starting at start, if the slot still holds it
+// rather than an older lap of the ring.
+func readSlot(f *os.File, t tier, start time.Time) (Point, bool, error) {
+	buf := make([]byte, recordSize)
+	if _, err := f.ReadAt(buf, slotOffset(t, start)); err != nil {
+		return Point{}, false, err
+	}
+	if int64(binary.LittleEndian.Uint64(buf)) != start.Unix() {
+		return Point{}, false, nil
+	}
+	return Point{
+		At:    start,
+		Count: int(binary.LittleEndian.Uint32(buf[8:])),
+		Min:   float64(math.Float32frombits(binary.LittleEndian.Uint32(buf[12:]))),
+		Avg:   float64(math.Float32frombits(binary.LittleEndian.Uint32(buf[16:]))),
+		Max:   float64(math.Float32frombits(binary.LittleEndian.Uint32(buf[20:]))),
+	}, true, nil
+}
+
+func writeSlot(f *os.File, t tier, p Point) error {
+	buf := make([]byte, recordSize)
+	binary.LittleEndian.PutUint64(buf, uint64(p.At.Unix()))
+	binary.LittleEndian.PutUint32(buf[8:], uint32(p.Count))
+	binary.LittleEndian.PutUint32(buf[12:], math.Float32bits(float32(p.Min)))
+	binary.LittleEndian.PutUint32(buf[16:], math.Float32bits(float32(p.Avg)))
+	binary.LittleEndian.PutUint32(buf[20:], math.Float32bits(float32(p.Max)))
+	_, err := f.WriteAt(buf, slotOffset(t, p.At))
+	return err
+}
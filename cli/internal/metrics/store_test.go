@@ -0,0 +1,154 @@
+package metrics
+
+import (
+	"math"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestStoreAggregatesBuckets(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now().Add(-time.Hour).Truncate(5 * time.Minute)
+	for i, v := range []float64{40, 50, 60} {
+		if err := s.Add("temp.cpu", start.Add(time.Duration(i)*time.Second), v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Add("temp.cpu", start.Add(Step), 45); err != nil {
+		t.Fatal(err)
+	}
+
+	points, step, err := s.Query("temp.cpu", start, start.Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if step != Step {
+		t.Errorf("step = %v, want %v", step, Step)
+	}
+	want := []Point{
+		{At: start, Count: 3, Min: 40, Avg: 50, Max: 60},
+		{At: start.Add(Step), Count: 1, Min: 45, Avg: 45, Max: 45},
+	}
+	if !slices.EqualFunc(points, want, equalPoint) {
+		t.Errorf("Query = %+v, want %+v", points, want)
+	}
+}
+
+func TestStoreCoarseTier(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Older than the 10s tier keeps, so the query falls back to 5m buckets
+	since := time.Now().Add(-30 * 24 * time.Hour).Truncate(5 * time.Minute)
+	for _, at := range []time.Time{since, since.Add(Step), since.Add(5 * time.Minute)} {
+		if err := s.Add("cpu", at, 10); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	points, step, err := s.Query("cpu", since, since.Add(10*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if step != 5*time.Minute {
+		t.Errorf("step = %v, want 5m", step)
+	}
+	if len(points) != 2 || points[0].Count != 2 || points[1].Count != 1 {
+		t.Errorf("Query = %+v, want buckets of 2 and 1 samples", points)
+	}
+}
+
+func TestStoreSkipsOlderLap(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := time.Now().Add(-time.Minute).Truncate(Step)
+	if err := s.Add("cpu", at, 1); err != nil {
+		t.Fatal(err)
+	}
+	// The same slot one full lap later replaces the bucket
+	if err := s.Add("cpu", at.Add(tiers[0].retention()), 2); err != nil {
+		t.Fatal(err)
+	}
+
+	points, _, err := s.Query("cpu", at, at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(points) != 0 {
+		t.Errorf("Query = %+v, want no points", points)
+	}
+}
+
+func TestStoreNames(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := s.Query("cpu", time.Now(), time.Now()); err == nil {
+		t.Error("Query of an unknown metric succeeded")
+	}
+	for _, name := range []string{"temp.gpu", "cpu", "temp.cpu"} {
+		if err := s.Add(name, time.Now(), 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	names, err := s.Names()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"cpu", "temp.cpu", "temp.gpu"}; !slices.Equal(names, want) {
+		t.Errorf("Names() = %v, want %v", names, want)
+	}
+	if got, want := match(names, "temp"), []string{"temp.cpu", "temp.gpu"}; !slices.Equal(got, want) {
+		t.Errorf("match(temp) = %v, want %v", got, want)
+	}
+}
+
+func TestColumns(t *testing.T) {
+	since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	until := since.Add(4 * time.Minute)
+	points := []Point{
+		{At: since, Max: 1},
+		{At: since.Add(30 * time.Second), Max: 3},
+		{At: since.Add(2 * time.Minute), Max: 2},
+		{At: until, Max: 5}, // the end falls in the last column
+	}
+
+	got := columns(points, since, until, 4)
+	want := []float64{3, math.NaN(), 2, 5}
+	if !slices.EqualFunc(got, want, sameFloat) {
+		t.Errorf("columns = %v, want %v", got, want)
+	}
+
+	if got := columns(points, since, until, 0); got != nil {
+		t.Errorf("columns with width 0 = %v, want nil", got)
+	}
+	if got := columns(points, until, since, 2); !slices.EqualFunc(got, []float64{math.NaN(), math.NaN()}, sameFloat) {
+		t.Errorf("columns of an empty span = %v, want all NaN", got)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	lo, avg, hi := Summarize([]Point{
+		{Count: 1, Min: 10, Avg: 10, Max: 10},
+		{Count: 3, Min: 2, Avg: 30, Max: 50},
+	})
+	if lo != 2 || avg != 25 || hi != 50 {
+		t.Errorf("Summarize = %v, %v, %v; want 2, 25, 50", lo, avg, hi)
+	}
+}
+
+func equalPoint(a, b Point) bool {
+	return a.At.Equal(b.At) && a.Count == b.Count && a.Min == b.Min && a.Avg == b.Avg && a.Max == b.Max
+}
+
+func sameFloat(a, b float64) bool {
+	return a == b || math.IsNaN(a) && math.IsNaN(b)
+}
@@ -0,0 +1,129 @@
+package remote
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// Target is a host reached over SSH, taken from an ssh:// Docker endpoint.
+type Target struct {
+	User string
+	Host string
+	Port string
+}
+
+// ParseSSH parses an ssh://[user@]host[:port] endpoint. Returns false for
+// any other scheme (unix://, tcp://, npipe://).
+func ParseSSH(endpoint string) (*Target, bool) {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Scheme != "ssh" || u.Hostname() == "" {
+		return nil, false
+	}
+	return &Target{
+		User: u.User.Username(),
+		Host: u.Hostname(),
+		Port: u.Port(),
+	}, true
+}
+
+// String returns the target as passed to ssh.
+func (t *Target) String() string {
+	if t.User != "" {
+		return t.User + "@" + t.Host
+	}
+	return t.Host
+}
+
+// Command builds an ssh invocation running bin with args on the target.
+// A TTY is requested when stdout is a terminal so monitors can redraw.
+func (t *Target) Command(bin string, args ...string) *exec.Cmd {
+	sshArgs := []string{}
+	if term.IsTerminal(int(os.Stdout.Fd())) {
+		sshArgs = append(sshArgs, "-t")
+	}
+	if t.Port != "" {
+		sshArgs = append(sshArgs, "-p", t.Port)
+	}
+	sshArgs = append(sshArgs, t.String(), "--")
+
+	remote := make([]string, 0, len(args)+1)
+	remote = append(remote, quote(bin))
+	for _, a := range args {
+		remote = append(remote, quote(a))
+	}
+	sshArgs = append(sshArgs, strings.Join(remote, " "))
+
+	return exec.Command("ssh", sshArgs...)
+}
+
+// Run executes flint on the target with the terminal attached and returns
+// the remote exit code.
+func (t *Target) Run(bin string, args ...string) (int, error) {
+	cmd := t.Command(bin, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode(), nil
+	}
+	if err != nil {
+		return 1, fmt.Errorf("running ssh %s: %w", t, err)
+	}
+	return 0, nil
+}
+
+// localFlags are global flags that only make sense on the calling machine
+// and are dropped before forwarding; the value says whether they take one.
+var localFlags = map[string]bool{
+	"-H": true, "--host": true,
+	"--context": true,
+	"-p":        true, "--project-dir": true,
+	"-f": true, "--file": true,
+}
+
+// ForwardArgs strips local-only global flags from a flint command line.
+func ForwardArgs(args []string) []string {
+	var out []string
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		if a == "--" {
+			out = append(out, args[i:]...)
+			break
+		}
+		name, _, hasValue := strings.Cut(a, "=")
+		if takesValue, ok := localFlags[name]; ok {
+			if takesValue && !hasValue {
+				i++
+			}
+			continue
+		}
+		// Short flag with attached value, e.g. -Hssh://pi@board
+		if len(a) > 2 && a[0] == '-' && a[1] != '-' {
+			if _, ok := localFlags[a[:2]]; ok {
+				continue
+			}
+		}
+		out = append(out, a)
+	}
+	return out
+}
+
+// quote single-quotes s for the remote POSIX shell.
+func quote(s string) string {
+	if s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return !(r == '-' || r == '_' || r == '.' || r == '/' || r == '=' || r == ':' || r == ',' ||
+			r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	}) < 0 {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,274 @@
+package server
+
+import (
+	"crypto/subtle"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/anibalnet/blackbeard/cli/internal/service"
+)
+
+//go:embed openapi.json
+var openAPISpec []byte
+
+// apiPrefix is the versioned root of the REST API.
+const apiPrefix = "/api/v1"
+
+// apiHandler returns the REST API routes. Every route except the OpenAPI
+// document requires "Authorization: Bearer <FLINT_API_TOKEN>".
+func (s *Server) apiHandler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET "+apiPrefix+"/openapi.json", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(openAPISpec)
+	})
+
+	auth := func(pattern string, h http.HandlerFunc) {
+		mux.Handle(pattern, s.requireToken(h, false))
+	}
+	auth("GET "+apiPrefix+"/status", s.apiStatus)
+	auth("GET "+apiPrefix+"/health", s.apiHealth)
+	auth("GET "+apiPrefix+"/resources", s.apiResources)
+	auth("POST "+apiPrefix+"/stack/{op}", s.apiStack)
+	auth("GET "+apiPrefix+"/backups", s.apiBackups)
+	auth("POST "+apiPrefix+"/backups", s.apiBackupRun)
+	auth("POST "+apiPrefix+"/backups/restore", s.apiRestore)
+	auth("GET "+apiPrefix+"/docker/disk", s.apiDockerDisk)
+	auth("GET "+apiPrefix+"/hw", s.apiHardware)
+	auth("GET "+apiPrefix+"/jobs", s.apiJobs)
+	auth("GET "+apiPrefix+"/jobs/{id}", s.apiJob)
+	// EventSource cannot set headers, so the event stream alone also takes
+	// the token as ?access_token=.
+	mux.Handle("GET "+apiPrefix+"/jobs/{id}/events", s.requireToken(s.apiJobEvents, true))
+
+	return mux
+}
+
+// requireToken checks the bearer token. With allowQuery, a missing header
+// falls back to the access_token query parameter.
+func (s *Server) requireToken(next http.HandlerFunc, allowQuery bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		want := s.svc.Config().APIToken
+		if want == "" {
+			writeError(w, http.StatusServiceUnavailable, errors.New("API disabled: set FLINT_API_TOKEN"))
+			return
+		}
+
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok && allowQuery {
+			got = r.URL.Query().Get("access_token")
+		}
+		if subtle.ConstantTimeCompare([]byte(got), []byte(want)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="flint"`)
+			writeError(w, http.StatusUnauthorized, errors.New("invalid or missing token"))
+			return
+		}
+		next(w, r)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+func (s *Server) apiStatus(w http.ResponseWriter, r *http.Request) {
+	containers, err := s.svc.Containers(r.Context())
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"project":    s.svc.Config().ProjectName,
+		"containers": containers,
+		"disabled":   s.svc.Config().DisabledServices,
+	})
+}
+
+// apiHealth reports healthy only when every container is running and no
+// health check is failing or still starting.
+func (s *Server) apiHealth(w http.ResponseWriter, r *http.Request) {
+	containers, err := s.svc.Containers(r.Context())
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+
+	healthy := len(containers) > 0
+	var unhealthy []string
+	for _, c := range containers {
+		if c.State != "running" || (c.Health != "" && c.Health != "healthy") {
+			healthy = false
+			unhealthy = append(unhealthy, c.Service)
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"healthy":    healthy,
+		"unhealthy":  unhealthy,
+		"containers": containers,
+	})
+}
+
+func (s *Server) apiResources(w http.ResponseWriter, r *http.Request) {
+	usage, err := s.svc.Resources(r.Context())
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, usage)
+}
+
+// decodeRequest reads an optional JSON body into req.
+func decodeRequest(r *http.Request, req *service.Request) error {
+	err := json.NewDecoder(io.LimitReader(r.Body, 1<<16)).Decode(req)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("invalid JSON body: %w", err)
+	}
+	return nil
+}
+
+func (s *Server) apiStack(w http.ResponseWriter, r *http.Request) {
+	var req service.Request
+	if err := decodeRequest(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	switch op := r.PathValue("op"); op {
+	case service.KindStart, service.KindStop, service.KindRestart, service.KindUpdate:
+		req.Kind = op
+	default:
+		writeError(w, http.StatusNotFound, fmt.Errorf("unknown stack operation %q", op))
+		return
+	}
+	s.startJob(w, r, service.Request{Kind: req.Kind, Service: req.Service})
+}
+
+func (s *Server) apiBackups(w http.ResponseWriter, _ *http.Request) {
+	backups, err := s.svc.Backups()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, backups)
+}
+
+func (s *Server) apiBackupRun(w http.ResponseWriter, r *http.Request) {
+	s.startJob(w, r, service.Request{Kind: service.KindBackup})
+}
+
+func (s *Server) apiRestore(w http.ResponseWriter, r *http.Request) {
+	var req service.Request
+	if err := decodeRequest(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	s.startJob(w, r, service.Request{Kind: service.KindRestore, Backup: req.Backup, Volume: req.Volume})
+}
+
+func (s *Server) startJob(w http.ResponseWriter, r *http.Request, req service.Request) {
+	job, err := s.svc.Start(req, "api "+clientAddr(r))
+	switch {
+	case errors.Is(err, service.ErrBusy):
+		writeError(w, http.StatusConflict, err)
+	case err != nil:
+		writeError(w, http.StatusBadRequest, err)
+	default:
+		w.Header().Set("Location", apiPrefix+"/jobs/"+job.ID)
+		writeJSON(w, http.StatusAccepted, job)
+	}
+}
+
+func (s *Server) apiDockerDisk(w http.ResponseWriter, r *http.Request) {
+	totals, err := s.svc.DockerDisk(r.Context())
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, totals)
+}
+
+func (s *Server) apiHardware(w http.ResponseWriter, _ *http.Request) {
+	h, err := s.svc.Hardware()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, h)
+}
+
+func (s *Server) apiJobs(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, s.svc.Jobs())
+}
+
+// apiJob returns a job; ?since=N returns only log lines from index N on,
+// so clients can poll for progress using the previous "lines" value.
+func (s *Server) apiJob(w http.ResponseWriter, r *http.Request) {
+	since, _ := strconv.Atoi(r.URL.Query().Get("since"))
+	job, _, err := s.svc.Job(r.PathValue("id"), since)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
+
+// apiJobEvents streams a job as server-sent events: one "log" event per
+// output line, then a "done" event carrying the final job.
+func (s *Server) apiJobEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, errors.New("streaming not supported"))
+		return
+	}
+
+	id := r.PathValue("id")
+	job, changed, err := s.svc.Job(id, 0)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+
+	sent := 0
+	for {
+		for _, line := range job.Log {
+			fmt.Fprintf(w, "event: log\ndata: %s\n\n", line)
+		}
+		sent += len(job.Log)
+
+		if !job.Running() {
+			job.Log = nil
+			data, _ := json.Marshal(job)
+			fmt.Fprintf(w, "event: done\ndata: %s\n\n", data)
+			flusher.Flush()
+			return
+		}
+		flusher.Flush()
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-changed:
+		}
+		if job, changed, err = s.svc.Job(id, sent); err != nil {
+			return
+		}
+	}
+}
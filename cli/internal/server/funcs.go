@@ -0,0 +1,89 @@
+package server
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+
+	"github.com/anibalnet/blackbeard/cli/internal/ui"
+)
+
+var funcs = template.FuncMap{
+	"bytes":    humanBytes,
+	"mhz":      func(hz int64) int64 { return hz / 1000000 },
+	"pct":      func(v float64) string { return fmt.Sprintf("%.1f%%", v) },
+	"ago":      ago,
+	"tempLvl":  tempLevel,
+	"stateLvl": stateLevel,
+	"rateLvl":  rateLevel,
+	"clock":    func(t time.Time) string { return t.Format("15:04:05") },
+}
+
+// humanBytes formats the int64 and uint64 sizes templates pass in.
+func humanBytes(v any) string {
+	switch n := v.(type) {
+	case int64:
+		return ui.FormatBytesSpaced(n)
+	case uint64:
+		return ui.FormatBytesSpaced(n)
+	}
+	return ui.FormatBytesSpaced(0)
+}
+
+func ago(v any) string {
+	var t time.Time
+	switch tv := v.(type) {
+	case time.Time:
+		t = tv
+	case *time.Time:
+		if tv == nil {
+			return ""
+		}
+		t = *tv
+	}
+	d := time.Since(t).Round(time.Second)
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds ago", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	default:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	}
+}
+
+// tempLevel uses the same thresholds as the CLI temperature colors.
+func tempLevel(c float64) string {
+	switch {
+	case c < 45:
+		return "ok"
+	case c < 60:
+		return "warn"
+	default:
+		return "bad"
+	}
+}
+
+func stateLevel(state, health string) string {
+	switch {
+	case health == "unhealthy" || state == "exited" || state == "dead":
+		return "bad"
+	case health == "starting" || state == "restarting" || state == "created":
+		return "warn"
+	case strings.EqualFold(state, "running"):
+		return "ok"
+	}
+	return "muted"
+}
+
+func rateLevel(rate float64) string {
+	switch {
+	case rate == 0:
+		return "muted"
+	case rate < 10:
+		return "warn"
+	default:
+		return "ok"
+	}
+}
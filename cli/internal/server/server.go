@@ -0,0 +1,289 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html/template"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/anibalnet/blackbeard/cli/internal/config"
+	dkr "github.com/anibalnet/blackbeard/cli/internal/docker"
+	"github.com/anibalnet/blackbeard/cli/internal/guard"
+	"github.com/anibalnet/blackbeard/cli/internal/metrics"
+	"github.com/anibalnet/blackbeard/cli/internal/service"
+	"github.com/anibalnet/blackbeard/cli/internal/ui"
+	"github.com/fatih/color"
+)
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+// Server is the flint web dashboard. It refreshes a snapshot in the
+// background so page loads never wait on Docker stats.
+type Server struct {
+	svc      *service.Service
+	tmpl     *template.Template
+	interval time.Duration
+	session  string // cookie value of a dashboard signed in with the API token
+
+	mu   sync.RWMutex
+	snap *service.Snapshot
+}
+
+// New creates a dashboard server refreshing data every interval.
+func New(svc *service.Service, interval time.Duration) (*Server, error) {
+	tmpl, err := template.New("").Funcs(funcs).ParseFS(templateFS, "templates/*.html")
+	if err != nil {
+		return nil, fmt.Errorf("parsing templates: %w", err)
+	}
+	session := make([]byte, 32)
+	if _, err := rand.Read(session); err != nil {
+		return nil, fmt.Errorf("generating session key: %w", err)
+	}
+	return &Server{svc: svc, tmpl: tmpl, interval: interval, session: hex.EncodeToString(session)}, nil
+}
+
+// sessionCookie holds the dashboard session after signing in.
+const sessionCookie = "flint_session"
+
+// Handler returns the dashboard routes. All links are relative so the
+// dashboard works both directly and behind nginx at /flint/.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", s.handleIndex)
+	mux.HandleFunc("GET /partial", s.handlePartial)
+	mux.HandleFunc("POST /action", s.handleAction)
+	mux.HandleFunc("POST /login", s.handleLogin)
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+	mux.Handle(apiPrefix+"/", s.apiHandler())
+	return mux
+}
+
+// Collect refreshes the snapshot until ctx is cancelled.
+func (s *Server) Collect(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		snap := s.svc.Snapshot(ctx)
+		s.mu.Lock()
+		s.snap = snap
+		s.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// pageData is what the templates render.
+type pageData struct {
+	*service.Snapshot
+	Job   *service.Job
+	Flash string
+
+	ActionsEnabled bool // FLINT_API_TOKEN is set
+	SignedIn       bool
+}
+
+func (s *Server) data(r *http.Request) pageData {
+	s.mu.RLock()
+	snap := s.snap
+	s.mu.RUnlock()
+	if snap == nil {
+		snap = s.svc.Snapshot(r.Context())
+	}
+	return pageData{
+		Snapshot:       snap,
+		Job:            s.svc.LastJob(),
+		Flash:          r.URL.Query().Get("msg"),
+		ActionsEnabled: s.svc.Config().APIToken != "",
+		SignedIn:       s.signedIn(r),
+	}
+}
+
+func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	s.render(w, "index.html", s.data(r))
+}
+
+func (s *Server) handlePartial(w http.ResponseWriter, r *http.Request) {
+	s.render(w, "dashboard", s.data(r))
+}
+
+func (s *Server) render(w http.ResponseWriter, name string, data pageData) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := s.tmpl.ExecuteTemplate(w, name, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (s *Server) handleAction(w http.ResponseWriter, r *http.Request) {
+	if s.svc.Config().APIToken == "" {
+		http.Error(w, "dashboard actions are disabled: set FLINT_API_TOKEN", http.StatusForbidden)
+		return
+	}
+	if !s.validToken(bearer(r)) && !(s.signedIn(r) && sameOrigin(r)) {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="flint"`)
+		http.Error(w, "sign in with the API token first", http.StatusUnauthorized)
+		return
+	}
+
+	req := service.Request{Kind: r.FormValue("name"), Service: r.FormValue("service")}
+	msg := fmt.Sprintf("Started %s %s", req.Kind, req.Service)
+	if _, err := s.svc.Start(req, clientAddr(r)); err != nil {
+		msg = err.Error()
+		if !errors.Is(err, service.ErrBusy) {
+			http.Error(w, msg, http.StatusBadRequest)
+			return
+		}
+	}
+	// Relative Location keeps the /flint/ prefix when proxied
+	w.Header().Set("Location", "./?msg="+url.QueryEscape(msg))
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+// handleLogin exchanges the API token for a session cookie, so the
+// dashboard's action buttons work without a bearer header.
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	if !sameOrigin(r) {
+		http.Error(w, "cross-origin request refused", http.StatusForbidden)
+		return
+	}
+	msg := "Signed in"
+	if s.validToken(r.FormValue("token")) {
+		http.SetCookie(w, &http.Cookie{
+			Name:     sessionCookie,
+			Value:    s.session,
+			HttpOnly: true,
+			Secure:   r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https",
+			SameSite: http.SameSiteStrictMode,
+		})
+	} else {
+		msg = "Invalid token"
+	}
+	w.Header().Set("Location", "./?msg="+url.QueryEscape(msg))
+	w.WriteHeader(http.StatusSeeOther)
+}
+
+// validToken compares a token with FLINT_API_TOKEN in constant time. No
+// token is valid while the API token is unset.
+func (s *Server) validToken(token string) bool {
+	want := s.svc.Config().APIToken
+	return want != "" && subtle.ConstantTimeCompare([]byte(token), []byte(want)) == 1
+}
+
+// signedIn reports whether the request carries the session cookie.
+func (s *Server) signedIn(r *http.Request) bool {
+	c, err := r.Cookie(sessionCookie)
+	return err == nil && s.svc.Config().APIToken != "" &&
+		subtle.ConstantTimeCompare([]byte(c.Value), []byte(s.session)) == 1
+}
+
+func bearer(r *http.Request) string {
+	token, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return token
+}
+
+// sameOrigin rejects form posts from other sites. Browsers always send
+// Origin on POST; its host must match the host the page was served from.
+// A request without Origin did not come from the dashboard and is refused.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return false
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	host := r.Header.Get("X-Forwarded-Host")
+	if host == "" {
+		host = r.Host
+	}
+	return u.Host == host
+}
+
+func clientAddr(r *http.Request) string {
+	if fwd := r.Header.Get("X-Real-IP"); fwd != "" {
+		return fwd
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// RunServe serves the dashboard until interrupted.
+func RunServe(ctx context.Context, cfg *config.Config, clients *dkr.Clients, p *ui.Printer, listen string, interval time.Duration, history, storageGuard bool) error {
+	// Output of actions is shown in the browser, not a terminal
+	color.NoColor = true
+
+	srv, err := New(service.New(cfg, clients), interval)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go srv.Collect(ctx)
+
+	if history {
+		store, err := metrics.Open(cfg.MetricsDir)
+		if err != nil {
+			return err
+		}
+		var once sync.Once
+		go metrics.NewRecorder(store).Run(ctx, func(err error) {
+			once.Do(func() { p.Warning(fmt.Sprintf("metrics history: %s", err)) })
+		})
+	}
+
+	if storageGuard {
+		p.Info(fmt.Sprintf("Storage guard: pausing qBittorrent at %d%% use of %s, resuming at %d%%",
+			cfg.GuardPausePct, cfg.DownloadsPath, cfg.GuardResumePct))
+		go guard.NewWatcher(cfg).Run(ctx,
+			func(msg string) { p.Warning(msg) },
+			func(err error) { p.Warning(fmt.Sprintf("storage guard: %s", err)) })
+	}
+
+	httpSrv := &http.Server{
+		Addr:              listen,
+		Handler:           srv.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = httpSrv.Shutdown(shutdownCtx)
+	}()
+
+	p.Info(fmt.Sprintf("Dashboard for %s listening on %s", cfg.ProjectName, listen))
+	if cfg.APIToken == "" {
+		p.Warning("REST API disabled: set FLINT_API_TOKEN to enable " + apiPrefix)
+	}
+	if err := httpSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("serving dashboard: %w", err)
+	}
+	p.Info("Dashboard stopped")
+	return nil
+}
@@ -0,0 +1,286 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anibalnet/blackbeard/cli/internal/audit"
+	"github.com/anibalnet/blackbeard/cli/internal/backup"
+	"github.com/anibalnet/blackbeard/cli/internal/stack"
+	"github.com/anibalnet/blackbeard/cli/internal/ui"
+)
+
+// Job kinds. Each maps to the flint command of the same name.
+const (
+	KindStart   = "start"
+	KindStop    = "stop"
+	KindRestart = "restart"
+	KindUpdate  = "update"
+	KindBackup  = "backup"
+	KindRestore = "restore"
+)
+
+// Job states.
+const (
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+)
+
+// maxJobs is how many finished jobs are kept in memory.
+const maxJobs = 50
+
+// ErrBusy is returned when a job is started while another is running.
+var ErrBusy = errors.New("another operation is already running")
+
+// ErrNotFound is returned for unknown job IDs.
+var ErrNotFound = errors.New("job not found")
+
+// Request describes a mutating operation to run as a job.
+type Request struct {
+	Kind    string `json:"kind"`
+	Service string `json:"service,omitempty"`
+	Backup  string `json:"backup,omitempty"` // restore: backup run, e.g. 20250101_040000
+	Volume  string `json:"volume,omitempty"` // restore: volume to replace
+}
+
+// Job is a point-in-time copy of an operation started outside the CLI.
+// Log holds the operation's output lines, which double as its progress.
+type Job struct {
+	ID string `json:"id"`
+	Request
+	State    string     `json:"state"`
+	Started  time.Time  `json:"started"`
+	Finished *time.Time `json:"finished,omitempty"`
+	Error    string     `json:"error,omitempty"`
+	Log      []string   `json:"log,omitempty"`
+	Lines    int        `json:"lines"` // total log lines, for ?since= polling
+}
+
+// Running reports whether the job has not finished yet.
+func (j *Job) Running() bool {
+	return j.State == JobRunning
+}
+
+// job is the live record behind a Job. It is the io.Writer the operation's
+// printer writes to.
+type job struct {
+	mu      sync.Mutex
+	info    Job
+	partial []byte
+	changed chan struct{} // closed and replaced whenever info changes
+}
+
+func (j *job) Write(p []byte) (int, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.partial = append(j.partial, p...)
+	for {
+		i := bytes.IndexByte(j.partial, '\n')
+		if i < 0 {
+			break
+		}
+		j.info.Log = append(j.info.Log, string(j.partial[:i]))
+		j.partial = j.partial[i+1:]
+	}
+	j.notify()
+	return len(p), nil
+}
+
+// notify wakes everyone waiting on changes. Callers hold j.mu.
+func (j *job) notify() {
+	close(j.changed)
+	j.changed = make(chan struct{})
+}
+
+func (j *job) finish(err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if len(j.partial) > 0 {
+		j.info.Log = append(j.info.Log, string(j.partial))
+		j.partial = nil
+	}
+	now := time.Now()
+	j.info.Finished = &now
+	j.info.State = JobSucceeded
+	if err != nil {
+		j.info.State = JobFailed
+		j.info.Error = err.Error()
+	}
+	j.notify()
+}
+
+// since returns a copy with log lines from index n on, and a channel that
+// is closed on the next change.
+func (j *job) since(n int) (*Job, <-chan struct{}) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	info := j.info
+	info.Lines = len(j.info.Log)
+	n = min(max(n, 0), len(j.info.Log))
+	info.Log = append([]string(nil), j.info.Log[n:]...)
+	return &info, j.changed
+}
+
+// command maps a request to the equivalent flint command line, which is
+// what the audit journal records.
+func (s *Service) command(req Request) (string, []string, error) {
+	switch req.Kind {
+	case KindStart, KindStop, KindRestart, KindUpdate:
+		if req.Service == "" {
+			return "stack " + req.Kind, []string{"stack", req.Kind}, nil
+		}
+		return "stack " + req.Kind + " <service>", []string{"stack", req.Kind, req.Service}, nil
+	case KindBackup:
+		return "backup all", []string{"backup", "all"}, nil
+	case KindRestore:
+		file, err := s.backupFile(req.Backup, req.Volume)
+		if err != nil {
+			return "", nil, err
+		}
+		return "backup restore <file> <name>", []string{"backup", "restore", file, req.Volume}, nil
+	}
+	return "", nil, fmt.Errorf("unknown operation %q", req.Kind)
+}
+
+// backupFile resolves a backup run and volume to an archive inside the
+// backup directory, refusing anything that would escape it.
+func (s *Service) backupFile(run, volume string) (string, error) {
+	if run == "" || volume == "" {
+		return "", fmt.Errorf("restore needs a backup and a volume")
+	}
+	for _, part := range []string{run, volume} {
+		if strings.ContainsAny(part, `/\`) || part == "." || part == ".." {
+			return "", fmt.Errorf("invalid name %q", part)
+		}
+	}
+	file := filepath.Join(s.cfg.BackupDir, run, volume+".tar.gz")
+	if _, err := os.Stat(file); err != nil {
+		return "", fmt.Errorf("no backup of %s in %s", volume, run)
+	}
+	return file, nil
+}
+
+// Start runs a request in the background and returns its job. Only one
+// operation runs at a time. origin describes who asked for it and is
+// recorded in the audit journal.
+func (s *Service) Start(req Request, origin string) (*Job, error) {
+	command, args, err := s.command(req)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	if s.running != nil {
+		s.mu.Unlock()
+		return nil, ErrBusy
+	}
+	s.seq++
+	started := time.Now()
+	j := &job{
+		info: Job{
+			ID:      fmt.Sprintf("%s-%d", started.Format("20060102-150405"), s.seq),
+			Request: req,
+			State:   JobRunning,
+			Started: started,
+		},
+		changed: make(chan struct{}),
+	}
+	s.running = j
+	s.jobs = append(s.jobs, j)
+	if len(s.jobs) > maxJobs {
+		s.jobs = s.jobs[len(s.jobs)-maxJobs:]
+	}
+	s.mu.Unlock()
+
+	entry := audit.NewEntry(command, args)
+	entry.Project = s.cfg.ProjectName
+	entry.Notes = append(entry.Notes, fmt.Sprintf("via flint serve (%s)", origin))
+
+	go func() {
+		ctx := audit.WithEntry(context.Background(), entry)
+		runErr := s.run(ctx, req, args, &ui.Printer{Out: j})
+
+		entry.Finish(runErr)
+		if err := audit.Append(s.cfg.AuditLog, entry); err != nil {
+			fmt.Fprintf(j, "audit log: %s\n", err)
+		}
+		j.finish(runErr)
+
+		s.mu.Lock()
+		s.running = nil
+		s.mu.Unlock()
+	}()
+
+	info, _ := j.since(0)
+	return info, nil
+}
+
+func (s *Service) run(ctx context.Context, req Request, args []string, p *ui.Printer) error {
+	switch req.Kind {
+	case KindStart:
+		return stack.RunStart(ctx, s.cfg, s.clients, p, req.Service)
+	case KindStop:
+		return stack.RunStop(ctx, s.cfg, s.clients, p, req.Service)
+	case KindRestart:
+		if req.Service == "" {
+			return stack.RunRestart(ctx, s.cfg, s.clients, p)
+		}
+		return stack.RunRestartService(ctx, s.cfg, s.clients, p, req.Service)
+	case KindUpdate:
+		return stack.RunUpdate(ctx, s.cfg, s.clients, p, req.Service, false)
+	case KindBackup:
+		return backup.RunBackupAll(ctx, s.cfg, s.clients, p)
+	case KindRestore:
+		return backup.RunRestore(ctx, s.cfg, s.clients, p, args[2], req.Volume, true)
+	}
+	return fmt.Errorf("unknown operation %q", req.Kind)
+}
+
+// Job returns a job with log lines from index since on, and a channel that
+// is closed when it next changes.
+func (s *Service) Job(id string, since int) (*Job, <-chan struct{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, j := range s.jobs {
+		if j.info.ID == id {
+			info, changed := j.since(since)
+			return info, changed, nil
+		}
+	}
+	return nil, nil, ErrNotFound
+}
+
+// Jobs returns recent jobs, newest first, without their logs.
+func (s *Service) Jobs() []*Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]*Job, 0, len(s.jobs))
+	for i := len(s.jobs) - 1; i >= 0; i-- {
+		info, _ := s.jobs[i].since(0)
+		info.Log = nil
+		out = append(out, info)
+	}
+	return out
+}
+
+// LastJob returns the running or most recent job with its full log, or nil.
+func (s *Service) LastJob() *Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.jobs) == 0 {
+		return nil
+	}
+	info, _ := s.jobs[len(s.jobs)-1].since(0)
+	return info
+}
@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anibalnet/blackbeard/cli/internal/backup"
+	"github.com/anibalnet/blackbeard/cli/internal/cleanup"
+	"github.com/anibalnet/blackbeard/cli/internal/config"
+	dkr "github.com/anibalnet/blackbeard/cli/internal/docker"
+	"github.com/anibalnet/blackbeard/cli/internal/hw"
+	"github.com/anibalnet/blackbeard/cli/internal/stack"
+	"github.com/docker/compose/v2/pkg/api"
+)
+
+// Service gives non-CLI frontends (the web dashboard) access to the same
+// stack, hardware and backup logic the flint commands use.
+type Service struct {
+	cfg     *config.Config
+	clients *dkr.Clients
+
+	mu      sync.Mutex
+	vpu     map[string]*vpuBaseline // previous reading per consumer
+	jobs    []*job
+	running *job
+	seq     int
+
+	// smartMu is separate so slow drive reads do not block jobs
+	smartMu sync.Mutex
+	smart   []*hw.SmartDrive
+	smartAt time.Time
+}
+
+// vpuBaseline is the VPU interrupt reading a consumer's next rates are
+// measured from.
+type vpuBaseline struct {
+	counts map[string]int64
+	at     time.Time
+}
+
+// smartInterval is how often drives are re-read for SMART health.
+const smartInterval = time.Hour
+
+// New creates a Service for one project.
+func New(cfg *config.Config, clients *dkr.Clients) *Service {
+	return &Service{cfg: cfg, clients: clients}
+}
+
+// Config returns the configuration the service was created with.
+func (s *Service) Config() *config.Config {
+	return s.cfg
+}
+
+// Snapshot is the state of the stack and board at one point in time.
+type Snapshot struct {
+	Time       time.Time              `json:"time"`
+	Project    string                 `json:"project"`
+	Containers []api.ContainerSummary `json:"containers"`
+	Disabled   []string               `json:"disabled,omitempty"`
+	Resources  []stack.ContainerUsage `json:"resources"`
+	Temps      []hw.TempReading       `json:"temps"`
+	GPU        hw.GPUInfo             `json:"gpu"`
+	VPU        []hw.VPUInterruptDelta `json:"vpu"`
+	Backups    []backup.Backup        `json:"backups"`
+	Alerts     []string               `json:"alerts,omitempty"`
+	Errors     []string               `json:"errors,omitempty"`
+}
+
+// Snapshot collects a fresh Snapshot. Failures of individual sources are
+// reported in Errors so the rest of the data is still usable.
+func (s *Service) Snapshot(ctx context.Context) *Snapshot {
+	snap := &Snapshot{
+		Time:     time.Now(),
+		Project:  s.cfg.ProjectName,
+		Disabled: s.cfg.DisabledServices,
+	}
+
+	var err error
+	if snap.Containers, err = stack.Containers(ctx, s.cfg, s.clients); err != nil {
+		snap.Errors = append(snap.Errors, "status: "+err.Error())
+	}
+	if snap.Resources, err = stack.ResourceUsage(ctx, s.cfg, s.clients); err != nil {
+		snap.Errors = append(snap.Errors, "resources: "+err.Error())
+	}
+	sort.Slice(snap.Resources, func(i, j int) bool { return snap.Resources[i].Name < snap.Resources[j].Name })
+
+	for _, t := range hw.ReadTemps() {
+		snap.Temps = append(snap.Temps, t)
+	}
+	sort.Slice(snap.Temps, func(i, j int) bool { return snap.Temps[i].Label < snap.Temps[j].Label })
+
+	snap.GPU = hw.ReadGPUInfo()
+	snap.VPU = s.vpuActivity("snapshot")
+
+	if snap.Backups, err = backup.ListBackups(s.cfg); err != nil {
+		snap.Backups = nil
+	}
+
+	if t := hw.ReadThrottle(); t.Throttled {
+		snap.Alerts = append(snap.Alerts, "Thermal throttling: "+strings.Join(t.Reasons, "; "))
+	}
+	for _, d := range s.Smart() {
+		if d.Verdict == hw.SmartWarning || d.Verdict == hw.SmartFailing {
+			snap.Alerts = append(snap.Alerts, fmt.Sprintf("Disk %s (%s) SMART %s: %s",
+				d.Device, d.Model, d.Verdict, strings.Join(d.Reasons, "; ")))
+		}
+	}
+
+	return snap
+}
+
+// vpuActivity returns VPU interrupt rates since consumer's previous call.
+// Each consumer keeps its own baseline, so the dashboard and the API do not
+// shorten each other's sampling window.
+func (s *Service) vpuActivity(consumer string) []hw.VPUInterruptDelta {
+	current := hw.ReadVPUInterrupts()
+	now := time.Now()
+
+	s.mu.Lock()
+	if s.vpu == nil {
+		s.vpu = map[string]*vpuBaseline{}
+	}
+	prev := s.vpu[consumer]
+	s.vpu[consumer] = &vpuBaseline{counts: current, at: now}
+	s.mu.Unlock()
+
+	if prev == nil {
+		prev = &vpuBaseline{counts: current, at: now}
+	}
+	deltas := hw.CalculateVPUDelta(prev.counts, current, 0)
+	if secs := now.Sub(prev.at).Seconds(); secs > 0 {
+		for i, d := range deltas {
+			if d.Delta > 0 {
+				deltas[i].RatePerSec = float64(d.Delta) / secs
+			}
+		}
+	}
+	sort.Slice(deltas, func(i, j int) bool { return deltas[i].Name < deltas[j].Name })
+	return deltas
+}
+
+// Smart returns SMART health of the drives, re-reading them at most once
+// an hour. Each read is also recorded in the drive history.
+func (s *Service) Smart() []*hw.SmartDrive {
+	s.smartMu.Lock()
+	defer s.smartMu.Unlock()
+	if s.smart != nil && time.Since(s.smartAt) < smartInterval {
+		return s.smart
+	}
+
+	drives := []*hw.SmartDrive{}
+	for _, device := range hw.SmartDevices() {
+		d := hw.ReadSmart(device, s.cfg.StateDir)
+		_ = hw.RecordSmart(s.cfg.StateDir, d)
+		drives = append(drives, d)
+	}
+	s.smart, s.smartAt = drives, time.Now()
+	return drives
+}
+
+// ContainerState is the status of one project container.
+type ContainerState struct {
+	Name    string `json:"name"`
+	Service string `json:"service"`
+	Image   string `json:"image"`
+	State   string `json:"state"`
+	Health  string `json:"health,omitempty"`
+	Status  string `json:"status"`
+}
+
+// Containers returns the state of every project container.
+func (s *Service) Containers(ctx context.Context) ([]ContainerState, error) {
+	containers, err := stack.Containers(ctx, s.cfg, s.clients)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]ContainerState, 0, len(containers))
+	for _, c := range containers {
+		out = append(out, ContainerState{
+			Name:    c.Name,
+			Service: c.Service,
+			Image:   c.Image,
+			State:   c.State,
+			Health:  c.Health,
+			Status:  c.Status,
+		})
+	}
+	return out, nil
+}
+
+// Resources samples CPU and memory usage of running containers.
+func (s *Service) Resources(ctx context.Context) ([]stack.ContainerUsage, error) {
+	return stack.ResourceUsage(ctx, s.cfg, s.clients)
+}
+
+// Backups lists backup runs on disk.
+func (s *Service) Backups() ([]backup.Backup, error) {
+	backups, err := backup.ListBackups(s.cfg)
+	if os.IsNotExist(err) {
+		return []backup.Backup{}, nil
+	}
+	return backups, err
+}
+
+// DockerDisk returns Docker disk usage totals.
+func (s *Service) DockerDisk(ctx context.Context) (*cleanup.DiskTotals, error) {
+	return cleanup.DiskUsage(ctx, s.clients)
+}
+
+// Hardware is a reading of the board's usage and sensors.
+type Hardware struct {
+	CPU   hw.CPUReading          `json:"cpu"`
+	Mem   hw.MemReading          `json:"mem"`
+	Temps []hw.TempReading       `json:"temps"`
+	GPU   hw.GPUInfo             `json:"gpu"`
+	VPU   []hw.VPUInterruptDelta `json:"vpu"`
+
+	Throttle hw.Throttle      `json:"throttle"`
+	Smart    []*hw.SmartDrive `json:"smart"`
+}
+
+// Hardware reads CPU, memory, temperatures and GPU/VPU activity. CPU usage
+// is sampled over one second.
+func (s *Service) Hardware() (*Hardware, error) {
+	h := &Hardware{GPU: hw.ReadGPUInfo(), VPU: s.vpuActivity("hardware"), Throttle: hw.ReadThrottle(), Smart: s.Smart()}
+
+	var err error
+	if h.CPU, err = hw.ReadCPU(time.Second); err != nil {
+		return nil, err
+	}
+	if h.Mem, err = hw.ReadMem(); err != nil {
+		return nil, err
+	}
+	for _, t := range hw.ReadTemps() {
+		h.Temps = append(h.Temps, t)
+	}
+	sort.Slice(h.Temps, func(i, j int) bool { return h.Temps[i].Label < h.Temps[j].Label })
+	return h, nil
+}
@@ -0,0 +1,414 @@
+package stack
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/anibalnet/blackbeard/cli/internal/audit"
+	"github.com/anibalnet/blackbeard/cli/internal/config"
+	dkr "github.com/anibalnet/blackbeard/cli/internal/docker"
+	"github.com/anibalnet/blackbeard/cli/internal/envfile"
+	"github.com/anibalnet/blackbeard/cli/internal/ui"
+	"github.com/docker/docker/api/types/network"
+	"gopkg.in/yaml.v3"
+)
+
+// Answers drives a non-interactive install. Omitted fields keep whatever
+// .env already has, so the same file can be applied repeatedly.
+type Answers struct {
+	DownloadsPath  string   `yaml:"downloads_path"`
+	ConfigBasePath string   `yaml:"config_base_path"`
+	Timezone       string   `yaml:"timezone"`
+	PUID           *int     `yaml:"puid"`
+	PGID           *int     `yaml:"pgid"`
+	GPUVideoGroup  string   `yaml:"gpu_video_group"`  // numeric GID or "auto"
+	GPURenderGroup string   `yaml:"gpu_render_group"` // numeric GID or "auto"
+	Services       []string `yaml:"services"`         // services to enable; empty means all
+
+	CreateDirectories *bool `yaml:"create_directories"` // config dirs, backups and downloads (default true)
+	CreateNetwork     *bool `yaml:"create_network"`     // default true
+}
+
+// Step status values used in the install report.
+const (
+	StepOK      = "ok"
+	StepChanged = "changed"
+	StepSkipped = "skipped"
+	StepFailed  = "failed"
+)
+
+// InstallStep is one entry of the machine-readable install report.
+type InstallStep struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// InstallReport summarises an answers-driven install.
+type InstallReport struct {
+	ProjectDir string        `json:"project_dir"`
+	Time       time.Time     `json:"time"`
+	Success    bool          `json:"success"`
+	Changed    bool          `json:"changed"`
+	Steps      []InstallStep `json:"steps"`
+}
+
+func (r *InstallReport) add(p *ui.Printer, name, status, detail string) {
+	r.Steps = append(r.Steps, InstallStep{Name: name, Status: status, Detail: detail})
+
+	msg := name
+	if detail != "" {
+		msg = fmt.Sprintf("%s: %s", name, detail)
+	}
+	switch status {
+	case StepChanged:
+		r.Changed = true
+		p.Success(msg)
+	case StepFailed:
+		p.Error(msg)
+	case StepSkipped:
+		p.Info(msg + " (skipped)")
+	default:
+		p.Info(msg)
+	}
+}
+
+// LoadAnswers reads and validates an answers file.
+func LoadAnswers(path string) (*Answers, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading answers: %w", err)
+	}
+
+	var a Answers
+	dec := yaml.NewDecoder(strings.NewReader(string(data)))
+	dec.KnownFields(true)
+	if err := dec.Decode(&a); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("parsing answers %s: %w", path, err)
+	}
+
+	for _, g := range []struct{ name, value string }{
+		{"gpu_video_group", a.GPUVideoGroup},
+		{"gpu_render_group", a.GPURenderGroup},
+	} {
+		if g.value == "" || g.value == "auto" {
+			continue
+		}
+		if _, err := strconv.Atoi(g.value); err != nil {
+			return nil, fmt.Errorf("%s must be a numeric GID or \"auto\", got %q", g.name, g.value)
+		}
+	}
+	if a.Timezone != "" {
+		if _, err := time.LoadLocation(a.Timezone); err != nil {
+			return nil, fmt.Errorf("unknown timezone %q", a.Timezone)
+		}
+	}
+	return &a, nil
+}
+
+// RunInstallAnswers applies an answers file without prompting and writes a
+// JSON report to reportPath ("-" for stdout, empty for none). When the report
+// goes to stdout, progress messages are sent to stderr instead.
+func RunInstallAnswers(ctx context.Context, cfg *config.Config, clients *dkr.Clients, p *ui.Printer, answersPath, reportPath string) error {
+	if reportPath == "-" {
+		p.Out = os.Stderr
+	}
+
+	answers, err := LoadAnswers(answersPath)
+	if err != nil {
+		return err
+	}
+
+	p.Header("Blackbeard Media Stack - Unattended Installation")
+	p.Info(fmt.Sprintf("Answers: %s", answersPath))
+	p.Println("")
+
+	report := &InstallReport{ProjectDir: cfg.ProjectDir, Time: time.Now()}
+
+	// Docker
+	if clients == nil {
+		report.add(p, "docker", StepFailed, "Docker is not available")
+	} else if ping, err := clients.Engine.Ping(ctx); err != nil {
+		report.add(p, "docker", StepFailed, err.Error())
+	} else {
+		report.add(p, "docker", StepOK, fmt.Sprintf("API v%s", ping.APIVersion))
+	}
+
+	// .env
+	env, created, err := ensureEnvFile(cfg)
+	switch {
+	case err != nil:
+		report.add(p, "env_file", StepFailed, err.Error())
+	case created:
+		audit.Note(ctx, "created .env from .env.example")
+		report.add(p, "env_file", StepChanged, "created from .env.example")
+	default:
+		report.add(p, "env_file", StepOK, cfg.EnvFile)
+	}
+
+	if env != nil {
+		applyAnswersToEnv(ctx, cfg, p, env, answers, report)
+	}
+	if len(answers.Services) > 0 {
+		applyAnswerServices(ctx, cfg, p, answers.Services, report)
+	}
+
+	// Network
+	if answers.CreateNetwork != nil && !*answers.CreateNetwork {
+		report.add(p, "network", StepSkipped, cfg.NetworkName)
+	} else if clients != nil {
+		if _, err := clients.Engine.NetworkInspect(ctx, cfg.NetworkName, network.InspectOptions{}); err == nil {
+			report.add(p, "network", StepOK, cfg.NetworkName)
+		} else if _, err := clients.Engine.NetworkCreate(ctx, cfg.NetworkName, network.CreateOptions{}); err != nil {
+			report.add(p, "network", StepFailed, err.Error())
+		} else {
+			audit.AddNetworks(ctx, cfg.NetworkName)
+			report.add(p, "network", StepChanged, "created "+cfg.NetworkName)
+		}
+	}
+
+	// Directories
+	if answers.CreateDirectories != nil && !*answers.CreateDirectories {
+		report.add(p, "directories", StepSkipped, "")
+	} else {
+		ensureAnswerDirs(ctx, cfg, p, report)
+	}
+
+	report.Success = true
+	for _, step := range report.Steps {
+		if step.Status == StepFailed {
+			report.Success = false
+		}
+	}
+
+	if err := writeReport(report, reportPath); err != nil {
+		return err
+	}
+
+	p.Println("")
+	if !report.Success {
+		return fmt.Errorf("unattended installation had failures")
+	}
+	if report.Changed {
+		p.Success("Installation applied")
+	} else {
+		p.Success("Nothing to change, installation already matches answers")
+	}
+	return nil
+}
+
+// ensureEnvFile loads .env, creating it from .env.example when missing.
+func ensureEnvFile(cfg *config.Config) (*envfile.File, bool, error) {
+	if cfg.EnvFileExists() {
+		env, err := envfile.Load(cfg.EnvFile)
+		return env, false, err
+	}
+	if !cfg.EnvExampleExists() {
+		return nil, false, fmt.Errorf(".env.example not found")
+	}
+	env, err := envfile.Load(cfg.EnvExample)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := env.Save(cfg.EnvFile); err != nil {
+		return nil, false, err
+	}
+	return env, true, nil
+}
+
+func applyAnswersToEnv(ctx context.Context, cfg *config.Config, p *ui.Printer, env *envfile.File, a *Answers, report *InstallReport) {
+	type setting struct{ key, value string }
+	var settings []setting
+
+	if a.DownloadsPath != "" {
+		// Saved absolute: other tools read DOWNLOADS_PATH from their own
+		// working directory, not the project's.
+		path := a.DownloadsPath
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(cfg.ProjectDir, path)
+		}
+		settings = append(settings, setting{"DOWNLOADS_PATH", path})
+	}
+	if a.ConfigBasePath != "" {
+		settings = append(settings, setting{"CONFIG_BASE_PATH", a.ConfigBasePath})
+	}
+	if a.Timezone != "" {
+		settings = append(settings, setting{"TZ", a.Timezone})
+	}
+	if a.PUID != nil {
+		settings = append(settings, setting{"PUID", strconv.Itoa(*a.PUID)})
+	}
+	if a.PGID != nil {
+		settings = append(settings, setting{"PGID", strconv.Itoa(*a.PGID)})
+	}
+	for _, g := range []struct{ key, answer, group string }{
+		{"GPU_VIDEO_GROUP", a.GPUVideoGroup, "video"},
+		{"GPU_RENDER_GROUP", a.GPURenderGroup, "render"},
+	} {
+		switch g.answer {
+		case "":
+		case "auto":
+			if gid := lookupGroupID(g.group); gid != "" {
+				settings = append(settings, setting{g.key, gid})
+			} else {
+				report.add(p, "env "+g.key, StepSkipped, fmt.Sprintf("group %q not found", g.group))
+			}
+		default:
+			settings = append(settings, setting{g.key, g.answer})
+		}
+	}
+
+	changed := false
+	for _, s := range settings {
+		current, ok := env.Get(s.key)
+		if ok && current == s.value {
+			report.add(p, "env "+s.key, StepOK, s.value)
+			continue
+		}
+		env.Set(s.key, s.value)
+		applyEnv(cfg, s.key, s.value)
+		audit.Note(ctx, ".env %s=%s", s.key, s.value)
+		report.add(p, "env "+s.key, StepChanged, s.value)
+		changed = true
+	}
+
+	if changed {
+		if err := env.Save(cfg.EnvFile); err != nil {
+			report.add(p, "env_file", StepFailed, fmt.Sprintf("writing .env: %s", err))
+		}
+	}
+}
+
+// applyAnswerServices disables every service the answers leave out of
+// their enabled list and enables the listed ones. Services with a compose
+// profile of their own are left alone unless listed.
+func applyAnswerServices(ctx context.Context, cfg *config.Config, p *ui.Printer, enabled []string, report *InstallReport) {
+	project, err := dkr.LoadProject(ctx, cfg.ComposeFiles, cfg.EnvFile, cfg.Instance)
+	if err != nil {
+		report.add(p, "services", StepFailed, err.Error())
+		return
+	}
+	all := project.AllServices()
+	for _, name := range enabled {
+		if _, ok := all[name]; !ok {
+			report.add(p, "services", StepFailed, fmt.Sprintf("unknown service %q", name))
+			return
+		}
+	}
+
+	var enable, disable []string
+	for _, name := range slices.Sorted(maps.Keys(all)) {
+		isDisabled := slices.Contains(cfg.DisabledServices, name)
+		switch {
+		case slices.Contains(enabled, name) && isDisabled:
+			enable = append(enable, name)
+		case !slices.Contains(enabled, name) && !isDisabled && len(all[name].Profiles) == 0:
+			disable = append(disable, name)
+		}
+	}
+	if len(enable)+len(disable) == 0 {
+		report.add(p, "services", StepOK, strings.Join(enabled, ","))
+		return
+	}
+	for _, change := range []struct {
+		names   []string
+		disable bool
+		verb    string
+	}{{enable, false, "enabled"}, {disable, true, "disabled"}} {
+		if len(change.names) == 0 {
+			continue
+		}
+		if err := setDisabled(cfg, change.names, change.disable); err != nil {
+			report.add(p, "services", StepFailed, err.Error())
+			return
+		}
+		audit.AddServices(ctx, change.names...)
+		audit.Note(ctx, "%s %s", change.verb, strings.Join(change.names, ","))
+		report.add(p, "services", StepChanged, fmt.Sprintf("%s %s", change.verb, strings.Join(change.names, ",")))
+	}
+}
+
+// applyEnv keeps the process environment and cfg in sync with a value just
+// written to .env. Compose interpolation prefers the OS environment, which
+// still holds the values loaded at startup.
+func applyEnv(cfg *config.Config, key, value string) {
+	os.Setenv(key, value)
+	switch key {
+	case "DOWNLOADS_PATH":
+		cfg.DownloadsPath = value
+	case "CONFIG_BASE_PATH":
+		if !filepath.IsAbs(value) {
+			value = filepath.Join(cfg.ProjectDir, value)
+		}
+		cfg.ConfigBasePath = value
+	case "TZ":
+		cfg.TZ = value
+	case "PUID":
+		cfg.PUID, _ = strconv.Atoi(value)
+	case "PGID":
+		cfg.PGID, _ = strconv.Atoi(value)
+	case "GPU_VIDEO_GROUP":
+		cfg.GPUVideoGroup = value
+	case "GPU_RENDER_GROUP":
+		cfg.GPURenderGroup = value
+	}
+}
+
+func ensureAnswerDirs(ctx context.Context, cfg *config.Config, p *ui.Printer, report *InstallReport) {
+	total, created, errs := EnsureConfigDirs(ctx, cfg, p)
+	switch {
+	case errs > 0:
+		report.add(p, "config_dirs", StepFailed, fmt.Sprintf("%d error(s)", errs))
+	case created > 0:
+		report.add(p, "config_dirs", StepChanged, fmt.Sprintf("created %d of %d", created, total))
+	default:
+		report.add(p, "config_dirs", StepOK, fmt.Sprintf("%d present", total))
+	}
+
+	for _, d := range []struct{ name, path string }{
+		{"backups_dir", cfg.BackupDir},
+		{"downloads_dir", cfg.DownloadsPath},
+	} {
+		if _, err := os.Stat(d.path); err == nil {
+			report.add(p, d.name, StepOK, d.path)
+			continue
+		}
+		if err := os.MkdirAll(d.path, 0755); err != nil {
+			report.add(p, d.name, StepFailed, err.Error())
+			continue
+		}
+		if cfg.PUID != os.Getuid() || cfg.PGID != os.Getgid() {
+			os.Chown(d.path, cfg.PUID, cfg.PGID)
+		}
+		audit.Note(ctx, "created %s", d.path)
+		report.add(p, d.name, StepChanged, "created "+d.path)
+	}
+}
+
+func writeReport(report *InstallReport, path string) error {
+	if path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if path == "-" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("writing report: %w", err)
+	}
+	return nil
+}
@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"github.com/anibalnet/blackbeard/cli/internal/audit"
 	"github.com/anibalnet/blackbeard/cli/internal/config"
 	dkr "github.com/anibalnet/blackbeard/cli/internal/docker"
 	"github.com/anibalnet/blackbeard/cli/internal/ui"
@@ -13,8 +15,9 @@ import (
 	"github.com/fatih/color"
 )
 
-// RunCheck verifies the installation status.
-func RunCheck(ctx context.Context, cfg *config.Config, clients *dkr.Clients, p *ui.Printer) error {
+// RunCheck verifies the installation status. With fixDevices, mismatched
+// transcoding device mappings are corrected in the compose override.
+func RunCheck(ctx context.Context, cfg *config.Config, clients *dkr.Clients, p *ui.Printer, fixDevices bool) error {
 	p.Header("Installation Status Check")
 
 	allOK := true
@@ -70,7 +73,7 @@ func RunCheck(ctx context.Context, cfg *config.Config, clients *dkr.Clients, p *
 
 	// Check config dirs (discovered from docker-compose.yml)
 	fmt.Printf("Config dirs:      ")
-	project, loadErr := dkr.LoadProject(ctx, cfg.ComposeFile, cfg.EnvFile)
+	project, loadErr := loadProject(ctx, cfg)
 	if loadErr != nil {
 		fmt.Println(warn("CANNOT READ COMPOSE"))
 		allOK = false
@@ -91,6 +94,16 @@ func RunCheck(ctx context.Context, cfg *config.Config, clients *dkr.Clients, p *
 		}
 	}
 
+	// Enabled services
+	if project != nil {
+		fmt.Printf("Services:         ")
+		if disabled := project.DisabledServiceNames(); len(disabled) > 0 {
+			fmt.Printf("%s (%d enabled, disabled: %s)\n", ok("OK"), len(project.Services), strings.Join(disabled, ", "))
+		} else {
+			fmt.Printf("%s (%d enabled)\n", ok("OK"), len(project.Services))
+		}
+	}
+
 	// Check downloads path
 	fmt.Printf("Downloads path:   ")
 	if _, err := os.Stat(cfg.DownloadsPath); err == nil {
@@ -108,6 +121,23 @@ func RunCheck(ctx context.Context, cfg *config.Config, clients *dkr.Clients, p *
 		fmt.Printf("%s (optional)\n", warn("NOT FOUND"))
 	}
 
+	// Check the VPU nodes mapped into Jellyfin are the ones on this host
+	var devices *DeviceCheck
+	if project != nil {
+		if check, err := CheckDevices(project, TranscodeService); err == nil {
+			fmt.Printf("VPU devices:      ")
+			switch {
+			case len(check.Host) == 0:
+				fmt.Printf("%s (optional)\n", warn("NOT FOUND"))
+			case check.OK():
+				fmt.Printf("%s (%d nodes mapped into %s)\n", ok("OK"), len(check.Host), check.Service)
+			default:
+				fmt.Println(warn("MISMATCH"))
+				devices = check
+			}
+		}
+	}
+
 	p.Println("")
 	if allOK {
 		p.Success("All checks passed! Ready to start.")
@@ -115,5 +145,20 @@ func RunCheck(ctx context.Context, cfg *config.Config, clients *dkr.Clients, p *
 		p.Warning("Some items need attention. Run: flint stack install")
 	}
 
+	switch {
+	case devices != nil && fixDevices:
+		path, err := FixDevices(cfg, devices)
+		if err != nil {
+			return err
+		}
+		audit.Note(ctx, "%s devices written to %s", devices.Service, path)
+		p.Success(fmt.Sprintf("Wrote %s device mappings to %s", devices.Service, path))
+		p.Info(fmt.Sprintf("Apply with: flint stack start %s", devices.Service))
+	case devices != nil:
+		printDeviceCheck(p, devices)
+	case fixDevices:
+		p.Info("Device mappings already match this host")
+	}
+
 	return nil
 }
@@ -0,0 +1,41 @@
+package stack
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/anibalnet/blackbeard/cli/internal/config"
+	"github.com/anibalnet/blackbeard/cli/internal/ui"
+)
+
+// RunConfig prints the fully merged and interpolated compose project.
+func RunConfig(ctx context.Context, cfg *config.Config, p *ui.Printer, format string, servicesOnly bool) error {
+	project, err := loadProject(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	if servicesOnly {
+		for _, name := range project.ServiceNames() {
+			p.Println(name)
+		}
+		return nil
+	}
+
+	var out []byte
+	switch format {
+	case "json":
+		out, err = project.MarshalJSON()
+	default:
+		out, err = project.MarshalYAML()
+	}
+	if err != nil {
+		return fmt.Errorf("rendering project: %w", err)
+	}
+
+	p.Printf("%s", out)
+	if len(out) > 0 && out[len(out)-1] != '\n' {
+		p.Println("")
+	}
+	return nil
+}
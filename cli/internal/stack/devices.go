@@ -0,0 +1,128 @@
+package stack
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/anibalnet/blackbeard/cli/internal/config"
+	"github.com/anibalnet/blackbeard/cli/internal/hw"
+	"github.com/anibalnet/blackbeard/cli/internal/ui"
+	"github.com/compose-spec/compose-go/v2/types"
+	"gopkg.in/yaml.v3"
+)
+
+// TranscodeService is the service hardware transcoding devices are mapped
+// into.
+const TranscodeService = "jellyfin"
+
+// DeviceCheck compares a service's device mappings with the RGA and VPU
+// nodes found on the host.
+type DeviceCheck struct {
+	Service  string
+	Host     []hw.VideoDevice // RGA and VPU nodes on the host
+	Problems []string
+}
+
+// OK reports whether the mappings match the host.
+func (c *DeviceCheck) OK() bool { return len(c.Problems) == 0 }
+
+// CheckDevices verifies that the video and media nodes mapped into service
+// are the RGA and VPU, and that none of them is missing. Device numbering
+// changes between kernels, so the compose file can silently go stale.
+func CheckDevices(project *types.Project, service string) (*DeviceCheck, error) {
+	svc, ok := project.Services[service]
+	if !ok {
+		return nil, fmt.Errorf("service %s is not enabled", service)
+	}
+
+	check := &DeviceCheck{Service: service, Host: hw.VPUDevices()}
+	byPath := map[string]hw.VideoDevice{}
+	for _, d := range hw.ReadVideoDevices() {
+		byPath[d.Path] = d
+	}
+
+	mapped := map[string]bool{}
+	for _, m := range svc.Devices {
+		mapped[m.Source] = true
+		if !strings.HasPrefix(m.Source, "/dev/video") && !strings.HasPrefix(m.Source, "/dev/media") {
+			continue
+		}
+		d, known := byPath[m.Source]
+		if _, err := os.Stat(m.Source); err != nil || !known {
+			check.Problems = append(check.Problems, fmt.Sprintf("%s is mapped but does not exist on this host", m.Source))
+			continue
+		}
+		if !d.VPU() {
+			check.Problems = append(check.Problems, fmt.Sprintf("%s is mapped but is %q, not the RGA or VPU", m.Source, d.Name))
+		}
+	}
+	for _, d := range check.Host {
+		if !mapped[d.Path] {
+			check.Problems = append(check.Problems, fmt.Sprintf("%s (%s, %s) is not mapped", d.Path, d.Role, d.Name))
+		}
+	}
+	return check, nil
+}
+
+// ProposedDevices is the corrected device list: /dev/dri when present and
+// every RGA and VPU node, each mapped to the same path.
+func (c *DeviceCheck) ProposedDevices() []string {
+	var devices []string
+	if _, err := os.Stat("/dev/dri"); err == nil {
+		devices = append(devices, "/dev/dri:/dev/dri")
+	}
+	for _, d := range c.Host {
+		devices = append(devices, d.Path+":"+d.Path)
+	}
+	return devices
+}
+
+// proposedNode renders the device list as YAML with each node's role as a
+// comment, like the comments in docker-compose.yml.
+func (c *DeviceCheck) proposedNode() *yaml.Node {
+	seq := &yaml.Node{Kind: yaml.SequenceNode}
+	for _, entry := range c.ProposedDevices() {
+		node := &yaml.Node{Kind: yaml.ScalarNode, Value: entry}
+		path, _, _ := strings.Cut(entry, ":")
+		if path == "/dev/dri" {
+			node.LineComment = "GPU (rendering)"
+		}
+		for _, d := range c.Host {
+			if d.Path == path {
+				node.LineComment = fmt.Sprintf("%s (%s)", d.Role, d.Name)
+			}
+		}
+		seq.Content = append(seq.Content, node)
+	}
+	return seq
+}
+
+// printDeviceCheck explains mismatches and shows the corrected list.
+func printDeviceCheck(p *ui.Printer, check *DeviceCheck) {
+	p.Println("")
+	p.Warning(fmt.Sprintf("Device mappings of %s do not match this host:", check.Service))
+	for _, problem := range check.Problems {
+		p.Printf("  - %s\n", problem)
+	}
+	p.Println("")
+	p.Println("Proposed devices:")
+	var out strings.Builder
+	enc := yaml.NewEncoder(&out)
+	enc.SetIndent(2)
+	_ = enc.Encode(map[string]any{"devices": check.proposedNode()})
+	for _, line := range strings.Split(strings.TrimRight(out.String(), "\n"), "\n") {
+		p.Printf("  %s\n", line)
+	}
+	p.Println("")
+	p.Info("Write them to the compose override with: flint stack check --fix-devices")
+}
+
+// FixDevices writes the corrected device list for the service to the
+// compose override file, replacing the mapping from the base file.
+func FixDevices(cfg *config.Config, check *DeviceCheck) (string, error) {
+	if len(check.Host) == 0 {
+		return "", fmt.Errorf("no RGA or VPU devices found on this host")
+	}
+	return SetOverride(cfg, check.Service, "devices", check.proposedNode(), true)
+}
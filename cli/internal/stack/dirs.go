@@ -6,15 +6,16 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/anibalnet/blackbeard/cli/internal/audit"
 	"github.com/anibalnet/blackbeard/cli/internal/config"
 	dkr "github.com/anibalnet/blackbeard/cli/internal/docker"
 	"github.com/anibalnet/blackbeard/cli/internal/ui"
 )
 
-// EnsureConfigDirs discovers config directories from docker-compose.yml
-// and creates any that are missing. Returns the number of dirs created and errors.
+// EnsureConfigDirs discovers config directories of enabled services from
+// docker-compose.yml and creates any that are missing. Returns the number of dirs created and errors.
 func EnsureConfigDirs(ctx context.Context, cfg *config.Config, p *ui.Printer) (total, created, errors int) {
-	project, err := dkr.LoadProject(ctx, cfg.ComposeFile, cfg.EnvFile)
+	project, err := loadProject(ctx, cfg)
 	if err != nil {
 		p.Error(fmt.Sprintf("reading docker-compose.yml: %s", err))
 		return 0, 0, 1
@@ -31,6 +32,7 @@ func EnsureConfigDirs(ctx context.Context, cfg *config.Config, p *ui.Printer) (t
 				errors++
 			} else {
 				p.Success(fmt.Sprintf("Created %s", dir))
+				audit.Note(ctx, "created %s", fullPath)
 				created++
 			}
 		} else {
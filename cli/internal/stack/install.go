@@ -10,8 +10,10 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/anibalnet/blackbeard/cli/internal/audit"
 	"github.com/anibalnet/blackbeard/cli/internal/config"
 	dkr "github.com/anibalnet/blackbeard/cli/internal/docker"
+	"github.com/anibalnet/blackbeard/cli/internal/envfile"
 	"github.com/anibalnet/blackbeard/cli/internal/ui"
 )
 
@@ -71,6 +73,7 @@ func RunInstall(ctx context.Context, cfg *config.Config, clients *dkr.Clients, p
 				errors++
 			} else {
 				p.Success("Created .env from .env.example")
+				audit.Note(ctx, "created .env from .env.example")
 				p.Warning("Remember to edit .env with your settings!")
 			}
 		}
@@ -89,20 +92,20 @@ func RunInstall(ctx context.Context, cfg *config.Config, clients *dkr.Clients, p
 	_ = currentUser
 
 	if cfg.EnvFileExists() {
-		envData, err := os.ReadFile(cfg.EnvFile)
+		env, err := envfile.Load(cfg.EnvFile)
 		if err == nil {
-			content := string(envData)
-			envPUID := extractEnvValue(content, "PUID")
-			envPGID := extractEnvValue(content, "PGID")
+			envPUID, _ := env.Get("PUID")
+			envPGID, _ := env.Get("PGID")
 
 			if envPUID != strconv.Itoa(currentUID) || envPGID != strconv.Itoa(currentGID) {
 				if ui.ConfirmYesNo("PUID/PGID in .env differ from current user. Update?", skipConfirm) {
-					content = replaceEnvValue(content, "PUID", strconv.Itoa(currentUID))
-					content = replaceEnvValue(content, "PGID", strconv.Itoa(currentGID))
-					if err := os.WriteFile(cfg.EnvFile, []byte(content), 0644); err != nil {
+					env.Set("PUID", strconv.Itoa(currentUID))
+					env.Set("PGID", strconv.Itoa(currentGID))
+					if err := env.Save(cfg.EnvFile); err != nil {
 						p.Error(fmt.Sprintf("updating .env: %s", err))
 					} else {
 						p.Success(fmt.Sprintf("Updated PUID=%d, PGID=%d", currentUID, currentGID))
+						audit.Note(ctx, ".env PUID=%d PGID=%d", currentUID, currentGID)
 					}
 				}
 			} else {
@@ -162,31 +165,40 @@ func RunInstall(ctx context.Context, cfg *config.Config, clients *dkr.Clients, p
 
 	// Detect GPU groups and update .env
 	if gpuAvailable && cfg.EnvFileExists() {
-		envData, _ := os.ReadFile(cfg.EnvFile)
-		content := string(envData)
+		env, err := envfile.Load(cfg.EnvFile)
+		if err != nil {
+			p.Error(fmt.Sprintf("reading .env: %s", err))
+			errors++
+			env = envfile.Parse("")
+		}
 		updated := false
 
 		videoGID := lookupGroupID("video")
 		renderGID := lookupGroupID("render")
 
 		if videoGID != "" {
-			envVideo := extractEnvValue(content, "GPU_VIDEO_GROUP")
+			envVideo, _ := env.Get("GPU_VIDEO_GROUP")
 			if envVideo != videoGID {
-				content = replaceEnvValue(content, "GPU_VIDEO_GROUP", videoGID)
+				env.Set("GPU_VIDEO_GROUP", videoGID)
 				updated = true
 				p.Info(fmt.Sprintf("  Updated GPU_VIDEO_GROUP=%s", videoGID))
+				audit.Note(ctx, ".env GPU_VIDEO_GROUP=%s", videoGID)
 			}
 		}
 		if renderGID != "" {
-			envRender := extractEnvValue(content, "GPU_RENDER_GROUP")
+			envRender, _ := env.Get("GPU_RENDER_GROUP")
 			if envRender != renderGID {
-				content = replaceEnvValue(content, "GPU_RENDER_GROUP", renderGID)
+				env.Set("GPU_RENDER_GROUP", renderGID)
 				updated = true
 				p.Info(fmt.Sprintf("  Updated GPU_RENDER_GROUP=%s", renderGID))
+				audit.Note(ctx, ".env GPU_RENDER_GROUP=%s", renderGID)
 			}
 		}
-		if updated {
-			os.WriteFile(cfg.EnvFile, []byte(content), 0644)
+		if updated && err == nil {
+			if err := env.Save(cfg.EnvFile); err != nil {
+				p.Error(fmt.Sprintf("updating .env: %s", err))
+				errors++
+			}
 		}
 	}
 
@@ -222,26 +234,6 @@ func RunInstall(ctx context.Context, cfg *config.Config, clients *dkr.Clients, p
 	return nil
 }
 
-func extractEnvValue(content, key string) string {
-	for _, line := range strings.Split(content, "\n") {
-		if strings.HasPrefix(line, key+"=") {
-			return strings.TrimPrefix(line, key+"=")
-		}
-	}
-	return ""
-}
-
-func replaceEnvValue(content, key, value string) string {
-	lines := strings.Split(content, "\n")
-	for i, line := range lines {
-		if strings.HasPrefix(line, key+"=") {
-			lines[i] = key + "=" + value
-			return strings.Join(lines, "\n")
-		}
-	}
-	return content
-}
-
 func lookupGroupID(name string) string {
 	group, err := user.LookupGroup(name)
 	if err != nil {
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/anibalnet/blackbeard/cli/internal/audit"
 	"github.com/anibalnet/blackbeard/cli/internal/config"
 	dkr "github.com/anibalnet/blackbeard/cli/internal/docker"
 	"github.com/anibalnet/blackbeard/cli/internal/ui"
@@ -21,6 +22,7 @@ func ensureNetwork(ctx context.Context, cfg *config.Config, clients *dkr.Clients
 			return fmt.Errorf("creating network: %w", err)
 		}
 		p.Success(fmt.Sprintf("Network '%s' created", cfg.NetworkName))
+		audit.AddNetworks(ctx, cfg.NetworkName)
 	}
 	return nil
 }
@@ -37,6 +39,10 @@ func RunStart(ctx context.Context, cfg *config.Config, clients *dkr.Clients, p *
 		return fmt.Errorf(".env file required")
 	}
 
+	if err := requireEnabled(cfg, service); err != nil {
+		return err
+	}
+
 	if err := ensureNetwork(ctx, cfg, clients, p); err != nil {
 		return err
 	}
@@ -52,14 +58,20 @@ func RunStart(ctx context.Context, cfg *config.Config, clients *dkr.Clients, p *
 		p.Header(fmt.Sprintf("Starting %s", service))
 	}
 
-	project, err := dkr.LoadProject(ctx, cfg.ComposeFile, cfg.EnvFile)
+	project, err := loadProject(ctx, cfg)
 	if err != nil {
 		return err
 	}
+	if err := checkPortConflicts(ctx, cfg, clients, project); err != nil {
+		return err
+	}
 
 	startOptions := api.StartOptions{}
 	if service != "" {
 		startOptions.Services = []string{service}
+		audit.AddServices(ctx, service)
+	} else {
+		audit.AddServices(ctx, project.ServiceNames()...)
 	}
 
 	err = clients.Compose.Up(ctx, project, api.UpOptions{
@@ -87,7 +99,7 @@ func RunStart(ctx context.Context, cfg *config.Config, clients *dkr.Clients, p *
 func RunStop(ctx context.Context, cfg *config.Config, clients *dkr.Clients, p *ui.Printer, service string) error {
 	if service == "" {
 		p.Header("Stopping Media Stack")
-		err := clients.Compose.Down(ctx, config.ProjectName, api.DownOptions{})
+		err := clients.Compose.Down(ctx, cfg.ProjectName, api.DownOptions{})
 		if err != nil {
 			return fmt.Errorf("stopping stack: %w", err)
 		}
@@ -97,8 +109,9 @@ func RunStop(ctx context.Context, cfg *config.Config, clients *dkr.Clients, p *u
 	}
 
 	p.Header(fmt.Sprintf("Stopping %s", service))
+	audit.AddServices(ctx, service)
 
-	err := clients.Compose.Stop(ctx, config.ProjectName, api.StopOptions{
+	err := clients.Compose.Stop(ctx, cfg.ProjectName, api.StopOptions{
 		Services: []string{service},
 	})
 	if err != nil {
@@ -121,8 +134,9 @@ func RunRestart(ctx context.Context, cfg *config.Config, clients *dkr.Clients, p
 // RunRestartService restarts a specific service.
 func RunRestartService(ctx context.Context, cfg *config.Config, clients *dkr.Clients, p *ui.Printer, service string) error {
 	p.Header(fmt.Sprintf("Restarting %s", service))
+	audit.AddServices(ctx, service)
 
-	err := clients.Compose.Restart(ctx, config.ProjectName, api.RestartOptions{
+	err := clients.Compose.Restart(ctx, cfg.ProjectName, api.RestartOptions{
 		Services: []string{service},
 	})
 	if err != nil {
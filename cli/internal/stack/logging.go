@@ -0,0 +1,62 @@
+package stack
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/anibalnet/blackbeard/cli/internal/audit"
+	"github.com/anibalnet/blackbeard/cli/internal/config"
+	"github.com/anibalnet/blackbeard/cli/internal/ui"
+	"gopkg.in/yaml.v3"
+)
+
+// RunLogsLimit writes a json-file logging limit for every service to the
+// compose override, so container logs rotate at maxSize and keep at most
+// maxFile files. Disabled services are included so they are covered once
+// enabled; services with another log driver are left alone.
+func RunLogsLimit(ctx context.Context, cfg *config.Config, p *ui.Printer, maxSize string, maxFile int) error {
+	p.Header("Limiting Container Logs")
+
+	project, err := loadProject(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	var path string
+	limited := 0
+	services := maps.Clone(project.Services)
+	maps.Copy(services, project.DisabledServices)
+	for _, name := range slices.Sorted(maps.Keys(services)) {
+		svc := services[name]
+		if svc.Logging != nil && svc.Logging.Driver != "" && svc.Logging.Driver != "json-file" {
+			p.Info(fmt.Sprintf("Skipping %s: uses the %s log driver", name, svc.Logging.Driver))
+			continue
+		}
+
+		var logging yaml.Node
+		if err := logging.Encode(map[string]any{
+			"driver": "json-file",
+			"options": map[string]string{
+				"max-size": maxSize,
+				"max-file": fmt.Sprint(maxFile),
+			},
+		}); err != nil {
+			return err
+		}
+		if path, err = SetOverride(cfg, name, "logging", &logging, false); err != nil {
+			return err
+		}
+		audit.AddServices(ctx, name)
+		limited++
+	}
+	if limited == 0 {
+		p.Info("No services to limit")
+		return nil
+	}
+
+	p.Success(fmt.Sprintf("Limited logs of %d services to %s x %d in %s", limited, maxSize, maxFile, path))
+	p.Info("Containers pick up the limit when recreated: flint stack start")
+	return nil
+}
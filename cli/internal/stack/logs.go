@@ -0,0 +1,37 @@
+package stack
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/anibalnet/blackbeard/cli/internal/config"
+	dkr "github.com/anibalnet/blackbeard/cli/internal/docker"
+	"github.com/anibalnet/blackbeard/cli/internal/ui"
+	"github.com/docker/compose/v2/cmd/formatter"
+	"github.com/docker/compose/v2/pkg/api"
+	"github.com/fatih/color"
+)
+
+// RunLogs follows logs for the entire stack or a specific service until interrupted.
+func RunLogs(ctx context.Context, cfg *config.Config, clients *dkr.Clients, p *ui.Printer, service string) error {
+	ctx, cancel := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	logOptions := api.LogOptions{
+		Tail:   "100",
+		Follow: true,
+	}
+	if service != "" {
+		logOptions.Services = []string{service}
+	}
+
+	consumer := formatter.NewLogConsumer(ctx, p.Out, os.Stderr, !color.NoColor, true, false)
+	err := clients.Compose.Logs(ctx, cfg.ProjectName, consumer, logOptions)
+	if err != nil && ctx.Err() == nil {
+		return fmt.Errorf("reading logs: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package stack
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/anibalnet/blackbeard/cli/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+// SetOverride sets services.<service>.<key> in the compose override file to
+// value, keeping everything else in the file. With replace the value is
+// tagged !override so it replaces the base file's value instead of being
+// merged with it. It returns the file written.
+func SetOverride(cfg *config.Config, service, key string, value *yaml.Node, replace bool) (string, error) {
+	path, doc, err := readOverride(cfg)
+	if err != nil {
+		return "", err
+	}
+	if replace {
+		value.Tag = "!override"
+	}
+	svc := mappingChild(mappingChild(doc.Content[0], "services"), service)
+	setMappingValue(svc, key, value)
+	return path, writeOverride(path, doc)
+}
+
+// UnsetOverride removes services.<service>.<key> from the compose override
+// file, and the service entry when nothing else is left in it. It returns
+// the file written, or "" when there was nothing to remove.
+func UnsetOverride(cfg *config.Config, service, key string) (string, error) {
+	path, doc, err := readOverride(cfg)
+	if err != nil {
+		return "", err
+	}
+	services := mappingChild(doc.Content[0], "services")
+	svc := mappingChild(services, service)
+	if !deleteMappingKey(svc, key) {
+		deleteMappingKey(services, service) // drop the entry mappingChild added
+		return "", nil
+	}
+	if len(svc.Content) == 0 {
+		deleteMappingKey(services, service)
+	}
+	return path, writeOverride(path, doc)
+}
+
+// readOverride parses the override file, or starts an empty document when
+// it does not exist yet.
+func readOverride(cfg *config.Config) (string, *yaml.Node, error) {
+	path, err := cfg.OverrideFile()
+	if err != nil {
+		return "", nil, err
+	}
+
+	var doc yaml.Node
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return "", nil, fmt.Errorf("reading %s: %w", path, err)
+	default:
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return "", nil, fmt.Errorf("parsing %s: %w", path, err)
+		}
+	}
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	if doc.Content[0].Kind != yaml.MappingNode {
+		return "", nil, fmt.Errorf("%s: top level is not a mapping", path)
+	}
+	return path, &doc, nil
+}
+
+func writeOverride(path string, doc *yaml.Node) error {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("encoding %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	return nil
+}
+
+// mappingChild returns the mapping under key, creating it if needed.
+func mappingChild(m *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key && m.Content[i+1].Kind == yaml.MappingNode {
+			return m.Content[i+1]
+		}
+	}
+	child := &yaml.Node{Kind: yaml.MappingNode}
+	setMappingValue(m, key, child)
+	return child
+}
+
+func setMappingValue(m *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			m.Content[i+1] = value
+			return
+		}
+	}
+	m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+}
+
+func deleteMappingKey(m *yaml.Node, key string) bool {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			m.Content = append(m.Content[:i], m.Content[i+2:]...)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,138 @@
+package stack
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/anibalnet/blackbeard/cli/internal/config"
+	dkr "github.com/anibalnet/blackbeard/cli/internal/docker"
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/docker/api/types/container"
+)
+
+// loadProject loads the compose project. Disabled services are in the
+// disabled profile, which compose already leaves out of the active set.
+func loadProject(ctx context.Context, cfg *config.Config) (*types.Project, error) {
+	project, err := dkr.LoadProject(ctx, cfg.ComposeFiles, cfg.EnvFile, cfg.Instance)
+	if err != nil {
+		return nil, err
+	}
+	if err := isolateInstance(cfg, project); err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+// isolateInstance lets a --project instance run beside the default stack.
+// The compose file pins container names, which only one project can use,
+// so instances drop them and compose names containers <project>-<service>-N.
+// Published host ports are shifted by FLINT_PORT_OFFSET.
+func isolateInstance(cfg *config.Config, project *types.Project) error {
+	if cfg.Instance == "" && cfg.PortOffset == 0 {
+		return nil
+	}
+	for name, svc := range project.Services {
+		if cfg.Instance != "" {
+			svc.ContainerName = ""
+		}
+		for i, port := range svc.Ports {
+			published, err := shiftPorts(port.Published, cfg.PortOffset)
+			if err != nil {
+				return fmt.Errorf("service %s: %w", name, err)
+			}
+			svc.Ports[i].Published = published
+		}
+		project.Services[name] = svc
+	}
+	return nil
+}
+
+// shiftPorts adds offset to a published port or port range such as
+// "8000-8010". An empty value lets Docker pick a port and is kept.
+func shiftPorts(published string, offset int) (string, error) {
+	if published == "" || offset == 0 {
+		return published, nil
+	}
+	parts := strings.Split(published, "-")
+	for i, part := range parts {
+		port, err := strconv.Atoi(part)
+		if err != nil {
+			return "", fmt.Errorf("invalid published port %q", published)
+		}
+		if port+offset < 1 || port+offset > 65535 {
+			return "", fmt.Errorf("FLINT_PORT_OFFSET %d moves port %d out of range", offset, port)
+		}
+		parts[i] = strconv.Itoa(port + offset)
+	}
+	return strings.Join(parts, "-"), nil
+}
+
+// checkPortConflicts fails when a host port the project publishes is
+// already published by a container of another compose project, which
+// happens when two instances run without a FLINT_PORT_OFFSET.
+func checkPortConflicts(ctx context.Context, cfg *config.Config, clients *dkr.Clients, project *types.Project) error {
+	containers, err := clients.Engine.ContainerList(ctx, container.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("listing containers: %w", err)
+	}
+	used := map[string]string{}
+	for _, c := range containers {
+		if c.Labels["com.docker.compose.project"] == project.Name {
+			continue
+		}
+		name := strings.TrimPrefix(firstName(c.Names), "/")
+		if p := c.Labels["com.docker.compose.project"]; p != "" {
+			name = fmt.Sprintf("%s (project %s)", name, p)
+		}
+		for _, port := range c.Ports {
+			if port.PublicPort != 0 {
+				used[fmt.Sprintf("%d/%s", port.PublicPort, port.Type)] = name
+			}
+		}
+	}
+
+	for _, name := range project.ServiceNames() {
+		for _, port := range project.Services[name].Ports {
+			protocol := port.Protocol
+			if protocol == "" {
+				protocol = "tcp"
+			}
+			key := port.Published + "/" + protocol
+			if owner, ok := used[key]; ok {
+				hint := "stop it first"
+				if cfg.Instance != "" {
+					hint = fmt.Sprintf("set FLINT_PORT_OFFSET in %s", cfg.EnvFile)
+				}
+				return fmt.Errorf("host port %s of %s is already published by %s: %s", key, name, owner, hint)
+			}
+		}
+	}
+	return nil
+}
+
+func firstName(names []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	return names[0]
+}
+
+// ResolveNames sets the project and network names and the disabled services
+// from the compose files, keeping the defaults when the project cannot be
+// loaded.
+func ResolveNames(ctx context.Context, cfg *config.Config) error {
+	project, err := dkr.LoadProject(ctx, cfg.ComposeFiles, cfg.EnvFile, cfg.Instance)
+	if err != nil {
+		return err
+	}
+	if project.Name != "" {
+		cfg.ProjectName = project.Name
+	}
+	if network := dkr.ExternalNetwork(project); network != "" {
+		cfg.NetworkName = network
+	}
+	cfg.DisabledServices = disabledServices(project)
+	return nil
+}
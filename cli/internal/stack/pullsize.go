@@ -0,0 +1,95 @@
+package stack
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/distribution/reference"
+	manifesttypes "github.com/docker/cli/cli/manifest/types"
+	"github.com/docker/distribution"
+)
+
+// manifestSource fetches image manifests from a registry.
+type manifestSource interface {
+	GetManifest(ctx context.Context, ref reference.Named) (manifesttypes.ImageManifest, error)
+	GetManifestList(ctx context.Context, ref reference.Named) ([]manifesttypes.ImageManifest, error)
+}
+
+// pullImage is an image about to be pulled and the registry digests of the
+// copy already on the daemon, if any.
+type pullImage struct {
+	Ref          string
+	LocalDigests []string // repo@sha256:... as in the image's RepoDigests
+}
+
+// pullSize estimates the bytes a pull downloads: the compressed size of every
+// layer in the images' manifests for goos/goarch, leaving out layers of the
+// local copies and counting layers shared between images once. Images that
+// cannot be looked up are returned as errors and add nothing.
+func pullSize(ctx context.Context, reg manifestSource, goos, goarch string, images []pullImage) (int64, []error) {
+	have := map[string]bool{}
+	for _, img := range images {
+		for _, d := range img.LocalDigests {
+			ref, err := reference.ParseNormalizedNamed(d)
+			if err != nil {
+				continue
+			}
+			// A local copy whose manifest is gone from the registry only
+			// means fewer layers are known to be present
+			layers, _ := imageLayers(ctx, reg, ref, goos, goarch)
+			for _, l := range layers {
+				have[l.Digest.String()] = true
+			}
+		}
+	}
+
+	var total int64
+	var errs []error
+	for _, img := range images {
+		ref, err := reference.ParseNormalizedNamed(img.Ref)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", img.Ref, err))
+			continue
+		}
+		layers, err := imageLayers(ctx, reg, reference.TagNameOnly(ref), goos, goarch)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", img.Ref, err))
+			continue
+		}
+		for _, l := range layers {
+			if !have[l.Digest.String()] {
+				have[l.Digest.String()] = true
+				total += l.Size
+			}
+		}
+	}
+	return total, errs
+}
+
+// imageLayers returns the layers of ref for goos/goarch, picking the platform
+// from a multi-arch manifest list.
+func imageLayers(ctx context.Context, reg manifestSource, ref reference.Named, goos, goarch string) ([]distribution.Descriptor, error) {
+	if list, err := reg.GetManifestList(ctx, ref); err == nil {
+		for _, m := range list {
+			if p := m.Descriptor.Platform; p != nil && p.OS == goos && p.Architecture == goarch {
+				return manifestLayers(m), nil
+			}
+		}
+		return nil, fmt.Errorf("no %s/%s image", goos, goarch)
+	}
+	m, err := reg.GetManifest(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	return manifestLayers(m), nil
+}
+
+func manifestLayers(m manifesttypes.ImageManifest) []distribution.Descriptor {
+	switch {
+	case m.SchemaV2Manifest != nil:
+		return m.SchemaV2Manifest.Layers
+	case m.OCIManifest != nil:
+		return m.OCIManifest.Layers
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package stack
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/distribution/reference"
+	manifesttypes "github.com/docker/cli/cli/manifest/types"
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/manifest/ocischema"
+	"github.com/docker/distribution/manifest/schema2"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// fakeRegistry serves manifest lists and single manifests by reference.
+type fakeRegistry struct {
+	lists     map[string][]manifesttypes.ImageManifest
+	manifests map[string]manifesttypes.ImageManifest
+}
+
+func (f *fakeRegistry) GetManifestList(_ context.Context, ref reference.Named) ([]manifesttypes.ImageManifest, error) {
+	if l, ok := f.lists[ref.String()]; ok {
+		return l, nil
+	}
+	return nil, errors.New("not a manifest list")
+}
+
+func (f *fakeRegistry) GetManifest(_ context.Context, ref reference.Named) (manifesttypes.ImageManifest, error) {
+	if m, ok := f.manifests[ref.String()]; ok {
+		return m, nil
+	}
+	return manifesttypes.ImageManifest{}, errors.New("manifest unknown")
+}
+
+func layer(name string, size int64) distribution.Descriptor {
+	return distribution.Descriptor{Digest: digest.FromString(name), Size: size}
+}
+
+func schema2Image(arch string, layers ...distribution.Descriptor) manifesttypes.ImageManifest {
+	return manifesttypes.ImageManifest{
+		Descriptor:       ocispec.Descriptor{Platform: &ocispec.Platform{OS: "linux", Architecture: arch}},
+		SchemaV2Manifest: &schema2.DeserializedManifest{Manifest: schema2.Manifest{Layers: layers}},
+	}
+}
+
+func ociImage(layers ...distribution.Descriptor) manifesttypes.ImageManifest {
+	return manifesttypes.ImageManifest{
+		OCIManifest: &ocischema.DeserializedManifest{Manifest: ocischema.Manifest{Layers: layers}},
+	}
+}
+
+func TestPullSize(t *testing.T) {
+	const oldDigest = "sha256:0000000000000000000000000000000000000000000000000000000000000001"
+	reg := &fakeRegistry{
+		lists: map[string][]manifesttypes.ImageManifest{
+			// Multi-arch: only the arm64 layers count
+			"docker.io/jellyfin/jellyfin:latest": {
+				schema2Image("amd64", layer("jf-amd64", 900)),
+				schema2Image("arm64", layer("debian", 30), layer("jf-base", 100), layer("jf-new", 200)),
+			},
+		},
+		manifests: map[string]manifesttypes.ImageManifest{
+			// The copy on the daemon already has the base layers
+			"docker.io/jellyfin/jellyfin@" + oldDigest: ociImage(layer("debian", 30), layer("jf-base", 100), layer("jf-old", 150)),
+			// Shares the debian layer, which is only counted once
+			"lscr.io/linuxserver/sonarr:4": ociImage(layer("debian", 30), layer("sonarr", 50)),
+		},
+	}
+
+	size, errs := pullSize(context.Background(), reg, "linux", "arm64", []pullImage{
+		{Ref: "jellyfin/jellyfin", LocalDigests: []string{"jellyfin/jellyfin@" + oldDigest}},
+		{Ref: "lscr.io/linuxserver/sonarr:4"},
+		{Ref: "ghcr.io/missing/image:1"},
+	})
+	if size != 200+50 {
+		t.Errorf("pullSize = %d, want %d", size, 250)
+	}
+	if len(errs) != 1 {
+		t.Errorf("errors = %v, want one for the missing image", errs)
+	}
+}
+
+func TestPullSizeNoPlatform(t *testing.T) {
+	reg := &fakeRegistry{lists: map[string][]manifesttypes.ImageManifest{
+		"docker.io/library/x:latest": {schema2Image("amd64", layer("x", 10))},
+	}}
+	size, errs := pullSize(context.Background(), reg, "linux", "arm64", []pullImage{{Ref: "x"}})
+	if size != 0 || len(errs) != 1 {
+		t.Errorf("pullSize = %d, %v; want 0 and an error", size, errs)
+	}
+}
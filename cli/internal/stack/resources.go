@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"sync"
 
 	"github.com/anibalnet/blackbeard/cli/internal/config"
 	dkr "github.com/anibalnet/blackbeard/cli/internal/docker"
@@ -13,12 +14,26 @@ import (
 	"github.com/docker/docker/api/types/filters"
 )
 
+// ContainerUsage is the CPU and memory usage of one running container.
+type ContainerUsage struct {
+	Name       string  `json:"name"`
+	Service    string  `json:"service"`
+	CPUPercent float64 `json:"cpu_percent"`
+	MemUsage   uint64  `json:"mem_usage"`
+	MemLimit   uint64  `json:"mem_limit"`
+	MemPercent float64 `json:"mem_percent"`
+	NetRx      uint64  `json:"net_rx"` // bytes received since the container started
+	NetTx      uint64  `json:"net_tx"`
+}
+
 // containerStats holds decoded Docker stats for a single container.
 type containerStats struct {
 	CPUPercent float64
 	MemUsage   uint64
 	MemLimit   uint64
 	MemPercent float64
+	NetRx      uint64
+	NetTx      uint64
 }
 
 func decodeStats(body io.ReadCloser) (*containerStats, error) {
@@ -42,6 +57,10 @@ func decodeStats(body io.ReadCloser) (*containerStats, error) {
 			Usage uint64 `json:"usage"`
 			Limit uint64 `json:"limit"`
 		} `json:"memory_stats"`
+		Networks map[string]struct {
+			RxBytes uint64 `json:"rx_bytes"`
+			TxBytes uint64 `json:"tx_bytes"`
+		} `json:"networks"`
 	}
 
 	if err := json.NewDecoder(body).Decode(&stats); err != nil {
@@ -61,71 +80,139 @@ func decodeStats(body io.ReadCloser) (*containerStats, error) {
 		memPercent = float64(stats.MemoryStats.Usage) / float64(stats.MemoryStats.Limit) * 100.0
 	}
 
-	return &containerStats{
+	result := &containerStats{
 		CPUPercent: cpuPercent,
 		MemUsage:   stats.MemoryStats.Usage,
 		MemLimit:   stats.MemoryStats.Limit,
 		MemPercent: memPercent,
-	}, nil
-}
-
-func formatBytes(b uint64) string {
-	const unit = 1024
-	if b < unit {
-		return fmt.Sprintf("%dB", b)
 	}
-	div, exp := uint64(unit), 0
-	for n := b / unit; n >= unit; n /= unit {
-		div *= unit
-		exp++
+	for _, n := range stats.Networks {
+		result.NetRx += n.RxBytes
+		result.NetTx += n.TxBytes
 	}
-	return fmt.Sprintf("%.1f%ciB", float64(b)/float64(div), "KMGTPE"[exp])
+	return result, nil
 }
 
-// RunResources shows resource usage for stack containers.
-func RunResources(ctx context.Context, cfg *config.Config, clients *dkr.Clients, p *ui.Printer) error {
-	p.Header("Resource Usage")
-
+// ResourceUsage samples CPU and memory usage of the project's running containers.
+func ResourceUsage(ctx context.Context, cfg *config.Config, clients *dkr.Clients) ([]ContainerUsage, error) {
 	containers, err := clients.Engine.ContainerList(ctx, container.ListOptions{
 		Filters: filters.NewArgs(
-			filters.Arg("label", "com.docker.compose.project="+config.ProjectName),
+			filters.Arg("label", "com.docker.compose.project="+cfg.ProjectName),
 		),
 	})
 	if err != nil {
-		return fmt.Errorf("listing containers: %w", err)
+		return nil, fmt.Errorf("listing containers: %w", err)
 	}
 
-	if len(containers) == 0 {
-		p.Warning("No running containers found")
-		return nil
+	// Each stats call waits for two samples, so query containers in parallel
+	usage := make([]*ContainerUsage, len(containers))
+	var wg sync.WaitGroup
+	for i, c := range containers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			statsBody, err := clients.Engine.ContainerStats(ctx, c.ID, false)
+			if err != nil {
+				return
+			}
+
+			stats, err := decodeStats(statsBody.Body)
+			if err != nil {
+				return
+			}
+
+			name := ""
+			if len(c.Names) > 0 {
+				name = c.Names[0][1:] // Remove leading /
+			}
+
+			usage[i] = &ContainerUsage{
+				Name:       name,
+				Service:    c.Labels["com.docker.compose.service"],
+				CPUPercent: stats.CPUPercent,
+				MemUsage:   stats.MemUsage,
+				MemLimit:   stats.MemLimit,
+				MemPercent: stats.MemPercent,
+				NetRx:      stats.NetRx,
+				NetTx:      stats.NetTx,
+			}
+		}()
 	}
+	wg.Wait()
 
-	table := ui.NewTable(p.Out, "NAME", "CPU %", "MEM USAGE / LIMIT", "MEM %")
+	result := make([]ContainerUsage, 0, len(usage))
+	for _, u := range usage {
+		if u != nil {
+			result = append(result, *u)
+		}
+	}
+	return result, nil
+}
 
+// NetworkTotals returns bytes received and sent by each compose service
+// since its containers started, summed over replicas. Containers sharing
+// the host network have no counters of their own and are left out.
+func NetworkTotals(ctx context.Context, cfg *config.Config, clients *dkr.Clients) (map[string][2]uint64, error) {
+	containers, err := clients.Engine.ContainerList(ctx, container.ListOptions{
+		Filters: filters.NewArgs(
+			filters.Arg("label", "com.docker.compose.project="+cfg.ProjectName),
+		),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing containers: %w", err)
+	}
+
+	totals := map[string][2]uint64{}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
 	for _, c := range containers {
-		statsBody, err := clients.Engine.ContainerStats(ctx, c.ID, false)
-		if err != nil {
-			continue
-		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// One-shot stats return immediately, without the CPU pre-sample
+			resp, err := clients.Engine.ContainerStatsOneShot(ctx, c.ID)
+			if err != nil {
+				return
+			}
+			stats, err := decodeStats(resp.Body)
+			if err != nil || stats.NetRx+stats.NetTx == 0 {
+				return
+			}
+			service := c.Labels["com.docker.compose.service"]
+			mu.Lock()
+			t := totals[service]
+			totals[service] = [2]uint64{t[0] + stats.NetRx, t[1] + stats.NetTx}
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	return totals, nil
+}
 
-		stats, err := decodeStats(statsBody.Body)
-		if err != nil {
-			continue
-		}
+// RunResources shows resource usage for stack containers.
+func RunResources(ctx context.Context, cfg *config.Config, clients *dkr.Clients, p *ui.Printer) error {
+	p.Header("Resource Usage")
 
-		name := ""
-		if len(c.Names) > 0 {
-			name = c.Names[0][1:] // Remove leading /
-		}
+	usage, err := ResourceUsage(ctx, cfg, clients)
+	if err != nil {
+		return err
+	}
+
+	if len(usage) == 0 {
+		p.Warning("No running containers found")
+		return nil
+	}
 
+	table := ui.NewTable(p.Out, "NAME", "CPU %", "MEM USAGE / LIMIT", "MEM %", "NET I/O")
+	for _, u := range usage {
 		table.Row(
-			name,
-			fmt.Sprintf("%.2f%%", stats.CPUPercent),
-			fmt.Sprintf("%s / %s", formatBytes(stats.MemUsage), formatBytes(stats.MemLimit)),
-			fmt.Sprintf("%.2f%%", stats.MemPercent),
+			u.Name,
+			fmt.Sprintf("%.2f%%", u.CPUPercent),
+			fmt.Sprintf("%s / %s", ui.FormatBytes(u.MemUsage), ui.FormatBytes(u.MemLimit)),
+			fmt.Sprintf("%.2f%%", u.MemPercent),
+			fmt.Sprintf("%s / %s", ui.FormatBytes(u.NetRx), ui.FormatBytes(u.NetTx)),
 		)
 	}
-
 	table.Flush()
 	return nil
 }
@@ -0,0 +1,149 @@
+package stack
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/anibalnet/blackbeard/cli/internal/audit"
+	"github.com/anibalnet/blackbeard/cli/internal/config"
+	dkr "github.com/anibalnet/blackbeard/cli/internal/docker"
+	"github.com/anibalnet/blackbeard/cli/internal/ui"
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/compose/v2/pkg/api"
+	"gopkg.in/yaml.v3"
+)
+
+// DisabledProfile is the compose profile disabled services are moved to.
+// Nothing activates it, so plain docker compose leaves them out as well.
+const DisabledProfile = "disabled"
+
+// RunEnable moves services out of the disabled profile.
+func RunEnable(ctx context.Context, cfg *config.Config, p *ui.Printer, services []string) error {
+	if err := checkServiceNames(ctx, cfg, services); err != nil {
+		return err
+	}
+
+	var enabled []string
+	for _, name := range services {
+		if slices.Contains(cfg.DisabledServices, name) {
+			enabled = append(enabled, name)
+		}
+	}
+	if len(enabled) == 0 {
+		p.Info(fmt.Sprintf("Already enabled: %s", strings.Join(services, ", ")))
+		return nil
+	}
+
+	if err := setDisabled(cfg, enabled, false); err != nil {
+		return err
+	}
+	audit.AddServices(ctx, enabled...)
+	audit.Note(ctx, "enabled %s", strings.Join(enabled, ","))
+
+	p.Success(fmt.Sprintf("Enabled %s", strings.Join(enabled, ", ")))
+	p.Info("Start with: flint stack start")
+	return nil
+}
+
+// RunDisable moves services to the disabled profile and stops their
+// containers.
+func RunDisable(ctx context.Context, cfg *config.Config, clients *dkr.Clients, p *ui.Printer, services []string) error {
+	if err := checkServiceNames(ctx, cfg, services); err != nil {
+		return err
+	}
+
+	var disabled []string
+	for _, name := range services {
+		if !slices.Contains(cfg.DisabledServices, name) {
+			disabled = append(disabled, name)
+		}
+	}
+	if len(disabled) == 0 {
+		p.Info(fmt.Sprintf("Already disabled: %s", strings.Join(services, ", ")))
+		return nil
+	}
+
+	if err := setDisabled(cfg, disabled, true); err != nil {
+		return err
+	}
+	audit.AddServices(ctx, disabled...)
+	audit.Note(ctx, "disabled %s", strings.Join(disabled, ","))
+	p.Success(fmt.Sprintf("Disabled %s", strings.Join(disabled, ", ")))
+
+	if clients != nil {
+		p.Info("Stopping disabled services...")
+		err := clients.Compose.Stop(ctx, cfg.ProjectName, api.StopOptions{Services: disabled})
+		if err != nil {
+			p.Warning(fmt.Sprintf("stopping %s: %s", strings.Join(disabled, ", "), err))
+		}
+	}
+	return nil
+}
+
+// checkServiceNames rejects names that are not defined in the compose file.
+func checkServiceNames(ctx context.Context, cfg *config.Config, services []string) error {
+	project, err := dkr.LoadProject(ctx, cfg.ComposeFiles, cfg.EnvFile, cfg.Instance)
+	if err != nil {
+		return err
+	}
+	all := slices.Sorted(maps.Keys(project.AllServices()))
+	for _, name := range services {
+		if !slices.Contains(all, name) {
+			return fmt.Errorf("unknown service %q (available: %s)", name, strings.Join(all, ", "))
+		}
+	}
+	return nil
+}
+
+// setDisabled assigns services to the disabled profile in the compose
+// override, or drops that assignment so their own profiles apply again.
+func setDisabled(cfg *config.Config, services []string, disable bool) error {
+	for _, name := range services {
+		var err error
+		if disable {
+			profiles := &yaml.Node{Kind: yaml.SequenceNode, Content: []*yaml.Node{
+				{Kind: yaml.ScalarNode, Value: DisabledProfile},
+			}}
+			_, err = SetOverride(cfg, name, "profiles", profiles, true)
+		} else {
+			_, err = UnsetOverride(cfg, name, "profiles")
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, name := range services {
+		if disable {
+			cfg.DisabledServices = append(cfg.DisabledServices, name)
+		} else {
+			cfg.DisabledServices = slices.DeleteFunc(cfg.DisabledServices, func(n string) bool { return n == name })
+		}
+	}
+	sort.Strings(cfg.DisabledServices)
+	return nil
+}
+
+// disabledServices lists the services in the disabled profile.
+func disabledServices(project *types.Project) []string {
+	var names []string
+	for name, svc := range project.AllServices() {
+		if slices.Contains(svc.Profiles, DisabledProfile) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// requireEnabled fails when a single-service command targets a disabled service.
+func requireEnabled(cfg *config.Config, service string) error {
+	if service != "" && slices.Contains(cfg.DisabledServices, service) {
+		return fmt.Errorf("service %s is disabled (enable it with: flint stack enable %s)", service, service)
+	}
+	return nil
+}
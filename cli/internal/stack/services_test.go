@@ -0,0 +1,91 @@
+package stack
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/anibalnet/blackbeard/cli/internal/config"
+	dkr "github.com/anibalnet/blackbeard/cli/internal/docker"
+)
+
+const testCompose = `services:
+  jellyfin:
+    image: jellyfin/jellyfin
+  sonarr:
+    image: lscr.io/linuxserver/sonarr
+  gluetun:
+    image: qmcgee/gluetun
+    profiles: [vpn]
+`
+
+func testProject(t *testing.T, override string) *config.Config {
+	t.Helper()
+	t.Setenv("COMPOSE_FILE", "")
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "docker-compose.yml"), []byte(testCompose), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if override != "" {
+		if err := os.WriteFile(filepath.Join(dir, "docker-compose.override.yml"), []byte(override), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg := &config.Config{ProjectDir: dir, ProjectName: "test", EnvFile: filepath.Join(dir, ".env")}
+	if err := cfg.ResolveComposeFiles(nil); err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+func loadDisabled(t *testing.T, cfg *config.Config) []string {
+	t.Helper()
+	if err := cfg.ResolveComposeFiles(nil); err != nil {
+		t.Fatal(err)
+	}
+	project, err := dkr.LoadProject(context.Background(), cfg.ComposeFiles, cfg.EnvFile, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if slices.Contains(project.ServiceNames(), "gluetun") {
+		t.Error("gluetun is active without its profile")
+	}
+	for _, name := range disabledServices(project) {
+		if slices.Contains(project.ServiceNames(), name) {
+			t.Errorf("disabled service %s is still in the active set", name)
+		}
+	}
+	return disabledServices(project)
+}
+
+func TestDisableUsesComposeProfile(t *testing.T) {
+	cfg := testProject(t, "services:\n  jellyfin:\n    environment:\n      TZ: UTC\n")
+
+	if err := setDisabled(cfg, []string{"jellyfin", "gluetun"}, true); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := loadDisabled(t, cfg), []string{"gluetun", "jellyfin"}; !slices.Equal(got, want) {
+		t.Fatalf("disabled = %v, want %v", got, want)
+	}
+
+	if err := setDisabled(cfg, []string{"jellyfin", "gluetun", "sonarr"}, false); err != nil {
+		t.Fatal(err)
+	}
+	if got := loadDisabled(t, cfg); len(got) != 0 {
+		t.Errorf("disabled after enabling = %v, want none", got)
+	}
+	if len(cfg.DisabledServices) != 0 {
+		t.Errorf("cfg.DisabledServices = %v, want none", cfg.DisabledServices)
+	}
+
+	// Enabling leaves the rest of the override as it was
+	data, err := os.ReadFile(filepath.Join(cfg.ProjectDir, "docker-compose.override.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), "services:\n  jellyfin:\n    environment:\n      TZ: UTC\n"; got != want {
+		t.Errorf("override = %q, want %q", got, want)
+	}
+}
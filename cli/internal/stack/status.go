@@ -10,19 +10,38 @@ import (
 	"github.com/docker/compose/v2/pkg/api"
 )
 
+// Containers returns every container of the project, running or not.
+func Containers(ctx context.Context, cfg *config.Config, clients *dkr.Clients) ([]api.ContainerSummary, error) {
+	return clients.Compose.Ps(ctx, cfg.ProjectName, api.PsOptions{
+		All: true,
+	})
+}
+
+// ContainerNames maps the IDs of running project containers to their names.
+func ContainerNames(ctx context.Context, cfg *config.Config, clients *dkr.Clients) (map[string]string, error) {
+	containers, err := clients.Compose.Ps(ctx, cfg.ProjectName, api.PsOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("listing containers: %w", err)
+	}
+	names := make(map[string]string, len(containers))
+	for _, c := range containers {
+		names[c.ID] = c.Name
+	}
+	return names, nil
+}
+
 // RunStatus shows container status.
 func RunStatus(ctx context.Context, cfg *config.Config, clients *dkr.Clients, p *ui.Printer) error {
 	p.Header("Stack Status")
 
-	containers, err := clients.Compose.Ps(ctx, config.ProjectName, api.PsOptions{
-		All: true,
-	})
+	containers, err := Containers(ctx, cfg, clients)
 	if err != nil {
 		return fmt.Errorf("getting status: %w", err)
 	}
 
 	if len(containers) == 0 {
-		p.Warning("No containers found for project 'media-stack'")
+		p.Warning(fmt.Sprintf("No containers found for project '%s'", cfg.ProjectName))
+		printDisabled(cfg, p)
 		return nil
 	}
 
@@ -41,22 +60,33 @@ func RunStatus(ctx context.Context, cfg *config.Config, clients *dkr.Clients, p
 	}
 	table.Flush()
 
+	printDisabled(cfg, p)
 	return nil
 }
 
+// printDisabled lists services left out via flint stack disable.
+func printDisabled(cfg *config.Config, p *ui.Printer) {
+	if len(cfg.DisabledServices) == 0 {
+		return
+	}
+	p.Println("")
+	p.Info("Disabled services:")
+	for _, name := range cfg.DisabledServices {
+		p.Println(fmt.Sprintf("  %s", name))
+	}
+}
+
 // RunHealth shows health check status.
 func RunHealth(ctx context.Context, cfg *config.Config, clients *dkr.Clients, p *ui.Printer) error {
 	p.Header("Health Status")
 
-	containers, err := clients.Compose.Ps(ctx, config.ProjectName, api.PsOptions{
-		All: true,
-	})
+	containers, err := Containers(ctx, cfg, clients)
 	if err != nil {
 		return fmt.Errorf("getting health: %w", err)
 	}
 
 	if len(containers) == 0 {
-		p.Warning("No containers found for project 'media-stack'")
+		p.Warning(fmt.Sprintf("No containers found for project '%s'", cfg.ProjectName))
 		return nil
 	}
 
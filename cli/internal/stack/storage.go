@@ -0,0 +1,49 @@
+package stack
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/anibalnet/blackbeard/cli/internal/config"
+	dkr "github.com/anibalnet/blackbeard/cli/internal/docker"
+	"github.com/anibalnet/blackbeard/cli/internal/hw"
+)
+
+// defaultDockerRoot is used when the Docker daemon cannot be asked.
+const defaultDockerRoot = "/var/lib/docker"
+
+// DockerRoot returns the Docker data-root, where pulled images are stored.
+// clients may be nil.
+func DockerRoot(ctx context.Context, clients *dkr.Clients) string {
+	if clients != nil {
+		if info, err := clients.Engine.Info(ctx); err == nil && info.DockerRootDir != "" {
+			return info.DockerRootDir
+		}
+	}
+	return defaultDockerRoot
+}
+
+// StoragePaths returns the directories the stack stores data in, resolved
+// against the project directory. clients may be nil.
+func StoragePaths(ctx context.Context, cfg *config.Config, clients *dkr.Clients) []hw.StoragePath {
+	abs := func(p string) string {
+		if p == "" || filepath.IsAbs(p) {
+			return p
+		}
+		return filepath.Join(cfg.ProjectDir, p)
+	}
+
+	dockerRoot := DockerRoot(ctx, clients)
+	var paths []hw.StoragePath
+	for _, sp := range []hw.StoragePath{
+		{Role: hw.RoleDownloads, Path: abs(cfg.DownloadsPath)},
+		{Role: hw.RoleConfig, Path: abs(cfg.ConfigBasePath)},
+		{Role: hw.RoleBackups, Path: abs(cfg.BackupDir)},
+		{Role: hw.RoleDockerRoot, Path: dockerRoot},
+	} {
+		if sp.Path != "" {
+			paths = append(paths, sp)
+		}
+	}
+	return paths
+}
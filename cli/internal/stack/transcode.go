@@ -0,0 +1,302 @@
+package stack
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/anibalnet/blackbeard/cli/internal/config"
+	dkr "github.com/anibalnet/blackbeard/cli/internal/docker"
+	"github.com/anibalnet/blackbeard/cli/internal/hw"
+	"github.com/anibalnet/blackbeard/cli/internal/ui"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/fatih/color"
+)
+
+// JellyfinFFmpeg is where the linuxserver Jellyfin image installs
+// jellyfin-ffmpeg.
+const JellyfinFFmpeg = "/usr/lib/jellyfin-ffmpeg/ffmpeg"
+
+// transcodeUser is the user Jellyfin runs as in the linuxserver image, so
+// device permissions are tested as Jellyfin sees them rather than as root.
+const transcodeUser = "abc"
+
+// transcodeSample is generated inside the container with the ffmpeg test
+// source, so no media has to be shipped or mounted.
+const transcodeSample = "/tmp/flint-transcode-sample.mkv"
+
+var (
+	ffmpegFPS   = regexp.MustCompile(`fps=\s*([\d.]+)`)
+	ffmpegFrame = regexp.MustCompile(`frame=\s*(\d+)`)
+)
+
+// TranscodeResult is the outcome of one ffmpeg run.
+type TranscodeResult struct {
+	Name     string
+	Hardware bool
+	OK       bool
+	Frames   int
+	FPS      float64
+	IRQs     int64 // VPU interrupts raised during the run
+	Output   string
+}
+
+// RunTranscodeTest checks that hardware transcoding works in the Jellyfin
+// container: it decodes and encodes a short H.264 sample with V4L2M2M,
+// compares against software decoding and watches the VPU interrupts.
+func RunTranscodeTest(ctx context.Context, cfg *config.Config, clients *dkr.Clients, p *ui.Printer, service, ffmpeg string, seconds int) error {
+	p.Header("Hardware Transcoding Test")
+
+	ctr, err := serviceContainer(ctx, cfg, clients, service)
+	if err != nil {
+		return err
+	}
+	inspect, err := clients.Engine.ContainerInspect(ctx, ctr)
+	if err != nil {
+		return fmt.Errorf("inspecting %s: %w", service, err)
+	}
+	if inspect.State == nil || !inspect.State.Running {
+		return fmt.Errorf("%s is not running; start it with: flint stack start %s", service, service)
+	}
+
+	p.Info(fmt.Sprintf("Generating a %ds 720p H.264 sample in %s...", seconds, service))
+	gen := []string{ffmpeg, "-hide_banner", "-y", "-f", "lavfi",
+		"-i", "testsrc2=size=1280x720:rate=30", "-t", strconv.Itoa(seconds),
+		"-c:v", "libx264", "-preset", "ultrafast", "-pix_fmt", "yuv420p", transcodeSample}
+	if out, code, err := execCapture(ctx, clients, ctr, transcodeUser, gen); err != nil {
+		return err
+	} else if code != 0 {
+		if strings.Contains(out, "no such file") || strings.Contains(out, "not found") {
+			return fmt.Errorf("jellyfin-ffmpeg not found at %s in %s (use --ffmpeg)", ffmpeg, service)
+		}
+		return fmt.Errorf("generating sample failed: %s", lastLine(out))
+	}
+	defer execCapture(context.Background(), clients, ctr, transcodeUser, []string{"rm", "-f", transcodeSample})
+
+	tests := []struct {
+		name     string
+		hardware bool
+		args     []string
+	}{
+		{"decode (software)", false, []string{"-i", transcodeSample, "-f", "null", "-"}},
+		{"decode (V4L2M2M)", true, []string{"-c:v", "h264_v4l2m2m", "-i", transcodeSample, "-f", "null", "-"}},
+		{"encode (V4L2M2M)", true, []string{"-i", transcodeSample, "-c:v", "h264_v4l2m2m", "-b:v", "4M", "-f", "null", "-"}},
+	}
+
+	var results []TranscodeResult
+	for _, t := range tests {
+		p.Info(fmt.Sprintf("Running %s...", t.name))
+		args := append([]string{ffmpeg, "-hide_banner", "-nostdin"}, t.args...)
+
+		before := hw.ReadVPUInterrupts()
+		out, code, err := execCapture(ctx, clients, ctr, transcodeUser, args)
+		if err != nil {
+			return err
+		}
+		r := TranscodeResult{Name: t.name, Hardware: t.hardware, OK: code == 0, Output: out}
+		for _, d := range hw.CalculateVPUDelta(before, hw.ReadVPUInterrupts(), 1) {
+			r.IRQs += max(d.Delta, 0)
+		}
+		if m := ffmpegFrame.FindAllStringSubmatch(out, -1); len(m) > 0 {
+			r.Frames, _ = strconv.Atoi(m[len(m)-1][1])
+		}
+		if m := ffmpegFPS.FindAllStringSubmatch(out, -1); len(m) > 0 {
+			r.FPS, _ = strconv.ParseFloat(m[len(m)-1][1], 64)
+		}
+		results = append(results, r)
+	}
+
+	p.Println("")
+	table := ui.NewTable(p.Out, "TEST", "RESULT", "FRAMES", "FPS", "VPU IRQs")
+	for _, r := range results {
+		result := color.New(color.FgGreen).Sprint("ok")
+		if !r.OK {
+			result = color.New(color.FgRed).Sprint("failed")
+		}
+		irqs := "-"
+		if r.Hardware {
+			irqs = strconv.FormatInt(r.IRQs, 10)
+		}
+		table.Row(r.Name, result, strconv.Itoa(r.Frames), fmt.Sprintf("%.1f", r.FPS), irqs)
+	}
+	table.Flush()
+
+	software, decode, encode := results[0], results[1], results[2]
+	irqsReadable := len(hw.ReadVPUInterrupts()) > 0
+	p.Println("")
+	decodeWorks := decode.OK && (decode.IRQs > 0 || !irqsReadable)
+	switch {
+	case decodeWorks && !irqsReadable:
+		p.Warning("Hardware decoding ran, but VPU interrupts are not readable to confirm the VPU did the work")
+	case decodeWorks:
+		p.Success(fmt.Sprintf("Hardware decoding works: %.1f fps on the VPU vs %.1f fps in software", decode.FPS, software.FPS))
+	case decode.OK:
+		p.Error("Decoding succeeded but raised no VPU interrupts: ffmpeg fell back to software")
+	default:
+		p.Error(fmt.Sprintf("Hardware decoding failed: %s", lastLine(decode.Output)))
+	}
+	if encode.OK && (encode.IRQs > 0 || !irqsReadable) {
+		p.Success(fmt.Sprintf("Hardware encoding works: %.1f fps", encode.FPS))
+	} else {
+		p.Warning("Hardware encoding is not available: Jellyfin will encode in software")
+	}
+
+	hints := transcodeHints(cfg, inspect.HostConfig, decode, encode)
+	if len(hints) > 0 {
+		p.Println("")
+		p.Println("Hints:")
+		for _, h := range hints {
+			p.Printf("  - %s\n", h)
+		}
+	}
+
+	if !decodeWorks {
+		return fmt.Errorf("hardware transcoding is not working in %s", service)
+	}
+	return nil
+}
+
+// transcodeHints explains failures from the container's device mappings,
+// its groups and the ffmpeg output.
+func transcodeHints(cfg *config.Config, hc *container.HostConfig, results ...TranscodeResult) []string {
+	var hints []string
+	failed := false
+	var output strings.Builder
+	for _, r := range results {
+		if !r.OK || r.IRQs == 0 {
+			failed = true
+			output.WriteString(r.Output)
+		}
+	}
+	if !failed || hc == nil {
+		return nil
+	}
+	out := output.String()
+
+	mapped := map[string]bool{}
+	for _, d := range hc.Devices {
+		mapped[d.PathOnHost] = true
+		// A mapped directory such as /dev/dri covers its nodes
+		mapped[strings.TrimSuffix(d.PathOnHost, "/")+"/"] = true
+	}
+	devices := append(listVPUDevices(), mediaDevices()...)
+	for _, dev := range devices {
+		if !mapped[dev] && !mapped[filepath.Dir(dev)+"/"] {
+			hints = append(hints, fmt.Sprintf("%s is not mapped into the container: run flint stack check --fix-devices", dev))
+		}
+	}
+
+	groups := map[string]bool{strconv.Itoa(cfg.PGID): true}
+	for _, g := range hc.GroupAdd {
+		groups[g] = true
+	}
+	renderNodes, _ := filepath.Glob("/dev/dri/renderD*")
+	for _, dev := range append(devices, renderNodes...) {
+		info, err := os.Stat(dev)
+		if err != nil {
+			continue
+		}
+		st, ok := info.Sys().(*syscall.Stat_t)
+		if !ok || info.Mode().Perm()&0o006 == 0o006 {
+			continue
+		}
+		gid := strconv.FormatUint(uint64(st.Gid), 10)
+		if !groups[gid] {
+			key := "GPU_VIDEO_GROUP"
+			if strings.HasPrefix(dev, "/dev/dri/") {
+				key = "GPU_RENDER_GROUP"
+			}
+			hints = append(hints, fmt.Sprintf("%s belongs to group %s, which the container is not in: set %s=%s in .env and recreate jellyfin", dev, gid, key, gid))
+			groups[gid] = true // one hint per group
+		}
+	}
+
+	switch {
+	case strings.Contains(out, "Permission denied"):
+		hints = append(hints, "ffmpeg was denied access to a device: check GPU_VIDEO_GROUP and GPU_RENDER_GROUP match the device groups (ls -ln /dev/video* /dev/dri)")
+	case strings.Contains(out, "Unknown decoder") || strings.Contains(out, "Unknown encoder"):
+		hints = append(hints, "this ffmpeg build has no V4L2M2M support: use the jellyfin-ffmpeg shipped with the image")
+	case strings.Contains(out, "Could not find a valid device"):
+		hints = append(hints, "ffmpeg found no V4L2 memory-to-memory device: map the VPU /dev/videoN nodes and check the hantro driver is loaded (lsmod | grep hantro)")
+	}
+	return slices.Compact(hints)
+}
+
+// mediaDevices returns the /dev/mediaN nodes the VPU uses for requests.
+func mediaDevices() []string {
+	paths, _ := filepath.Glob("/dev/media[0-9]*")
+	return paths
+}
+
+// serviceContainer returns the ID of a running or stopped container of a
+// compose service.
+func serviceContainer(ctx context.Context, cfg *config.Config, clients *dkr.Clients, service string) (string, error) {
+	containers, err := clients.Engine.ContainerList(ctx, container.ListOptions{
+		All: true,
+		Filters: filters.NewArgs(
+			filters.Arg("label", "com.docker.compose.project="+cfg.ProjectName),
+			filters.Arg("label", "com.docker.compose.service="+service),
+		),
+	})
+	if err != nil {
+		return "", fmt.Errorf("listing containers: %w", err)
+	}
+	if len(containers) == 0 {
+		return "", fmt.Errorf("no container found for service %s", service)
+	}
+	return containers[0].ID, nil
+}
+
+// execCapture runs cmd in a container and returns its combined output and
+// exit code.
+func execCapture(ctx context.Context, clients *dkr.Clients, containerID, user string, cmd []string) (string, int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	exec, err := clients.Engine.ContainerExecCreate(ctx, containerID, container.ExecOptions{
+		User:         user,
+		Cmd:          cmd,
+		AttachStdout: true,
+		AttachStderr: true,
+	})
+	if err != nil {
+		return "", 0, fmt.Errorf("creating exec: %w", err)
+	}
+	resp, err := clients.Engine.ContainerExecAttach(ctx, exec.ID, container.ExecAttachOptions{})
+	if err != nil {
+		return "", 0, fmt.Errorf("attaching to exec: %w", err)
+	}
+	defer resp.Close()
+
+	var out bytes.Buffer
+	if _, err := stdcopy.StdCopy(&out, &out, resp.Reader); err != nil {
+		return "", 0, fmt.Errorf("reading exec output: %w", err)
+	}
+	info, err := clients.Engine.ContainerExecInspect(ctx, exec.ID)
+	if err != nil {
+		return "", 0, fmt.Errorf("inspecting exec: %w", err)
+	}
+	return out.String(), info.ExitCode, nil
+}
+
+// lastLine returns the last non-empty line of ffmpeg output, usually the
+// error. Progress lines end in carriage returns, not newlines.
+func lastLine(out string) string {
+	lines := strings.FieldsFunc(out, func(r rune) bool { return r == '\n' || r == '\r' })
+	for i := len(lines) - 1; i >= 0; i-- {
+		if line := strings.TrimSpace(lines[i]); line != "" {
+			return line
+		}
+	}
+	return "no output"
+}
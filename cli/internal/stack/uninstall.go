@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/anibalnet/blackbeard/cli/internal/audit"
 	"github.com/anibalnet/blackbeard/cli/internal/config"
 	dkr "github.com/anibalnet/blackbeard/cli/internal/docker"
 	"github.com/anibalnet/blackbeard/cli/internal/ui"
@@ -23,14 +24,17 @@ func RunUninstall(ctx context.Context, cfg *config.Config, clients *dkr.Clients,
 
 	if !ui.ConfirmTypeFull("Are you sure?", "yes", skipConfirm) {
 		p.Info("Uninstall cancelled")
+		audit.Cancelled(ctx)
 		return nil
 	}
 
 	p.Info("Stopping containers...")
-	_ = clients.Compose.Down(ctx, config.ProjectName, api.DownOptions{})
+	_ = clients.Compose.Down(ctx, cfg.ProjectName, api.DownOptions{})
 
 	p.Info("Removing network...")
-	_ = clients.Engine.NetworkRemove(ctx, cfg.NetworkName)
+	if err := clients.Engine.NetworkRemove(ctx, cfg.NetworkName); err == nil {
+		audit.AddNetworks(ctx, cfg.NetworkName)
+	}
 
 	p.Success("Uninstall completed")
 	p.Info("Config directories preserved in ./config/")
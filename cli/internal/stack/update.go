@@ -4,21 +4,29 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/anibalnet/blackbeard/cli/internal/audit"
 	"github.com/anibalnet/blackbeard/cli/internal/config"
 	dkr "github.com/anibalnet/blackbeard/cli/internal/docker"
+	"github.com/anibalnet/blackbeard/cli/internal/guard"
 	"github.com/anibalnet/blackbeard/cli/internal/ui"
+	"github.com/compose-spec/compose-go/v2/types"
 	"github.com/docker/compose/v2/pkg/api"
 )
 
-// RunUpdate pulls new images and recreates containers.
-func RunUpdate(ctx context.Context, cfg *config.Config, clients *dkr.Clients, p *ui.Printer, service string) error {
+// RunUpdate pulls new images and recreates containers. Unless
+// skipSpaceCheck is set, it first checks the Docker disk has room.
+func RunUpdate(ctx context.Context, cfg *config.Config, clients *dkr.Clients, p *ui.Printer, service string, skipSpaceCheck bool) error {
+	if err := requireEnabled(cfg, service); err != nil {
+		return err
+	}
+
 	if service == "" {
 		p.Header("Updating Stack Images")
 	} else {
 		p.Header(fmt.Sprintf("Updating %s", service))
 	}
 
-	project, err := dkr.LoadProject(ctx, cfg.ComposeFile, cfg.EnvFile)
+	project, err := loadProject(ctx, cfg)
 	if err != nil {
 		return err
 	}
@@ -30,6 +38,17 @@ func RunUpdate(ctx context.Context, cfg *config.Config, clients *dkr.Clients, p
 		}
 	}
 
+	for _, svc := range project.Services {
+		audit.AddServices(ctx, svc.Name)
+		audit.AddImages(ctx, svc.Image)
+	}
+
+	if !skipSpaceCheck {
+		if err := checkPullSpace(ctx, cfg, clients, p, project); err != nil {
+			return fmt.Errorf("%w (use --skip-space-check to pull anyway)", err)
+		}
+	}
+
 	err = clients.Compose.Pull(ctx, project, api.PullOptions{})
 	if err != nil {
 		return fmt.Errorf("pulling images: %w", err)
@@ -62,3 +81,39 @@ func RunUpdate(ctx context.Context, cfg *config.Config, clients *dkr.Clients, p
 	p.Success(fmt.Sprintf("%s updated successfully", service))
 	return nil
 }
+
+// checkPullSpace refuses to pull when the images' new layers would eat into
+// the disk reserve of the Docker data-root. The download size is estimated
+// from the registry manifests; extracted layers take somewhat more, which
+// the reserve absorbs. The data-root of a daemon on another host cannot be
+// checked.
+func checkPullSpace(ctx context.Context, cfg *config.Config, clients *dkr.Clients, p *ui.Printer, project *types.Project) error {
+	if !clients.Local() {
+		p.Info("Skipping free-space check: Docker runs on another host")
+		return nil
+	}
+
+	version, err := clients.Engine.ServerVersion(ctx)
+	if err != nil {
+		return fmt.Errorf("getting Docker version: %w", err)
+	}
+	var images []pullImage
+	seen := map[string]bool{}
+	for _, svc := range project.Services {
+		if svc.Image == "" || seen[svc.Image] {
+			continue
+		}
+		seen[svc.Image] = true
+		img := pullImage{Ref: svc.Image}
+		if local, _, err := clients.Engine.ImageInspectWithRaw(ctx, svc.Image); err == nil {
+			img.LocalDigests = local.RepoDigests
+		}
+		images = append(images, img)
+	}
+
+	need, errs := pullSize(ctx, clients.Registry(), version.Os, version.Arch, images)
+	for _, err := range errs {
+		p.Warning(fmt.Sprintf("Could not size image %s", err))
+	}
+	return guard.Require(p, "image pull", DockerRoot(ctx, clients), need, cfg.DiskReserve)
+}
@@ -2,6 +2,7 @@ package stack
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/anibalnet/blackbeard/cli/internal/config"
 	dkr "github.com/anibalnet/blackbeard/cli/internal/docker"
@@ -12,7 +13,11 @@ import (
 func RunValidate(ctx context.Context, cfg *config.Config, p *ui.Printer) error {
 	p.Header("Validating Configuration")
 
-	_, err := dkr.LoadProject(ctx, cfg.ComposeFile, cfg.EnvFile)
+	for _, f := range cfg.ComposeFiles {
+		p.Info(fmt.Sprintf("Compose file: %s", f))
+	}
+
+	_, err := dkr.LoadProject(ctx, cfg.ComposeFiles, cfg.EnvFile, cfg.Instance)
 	if err != nil {
 		p.Error("Docker Compose configuration has errors")
 		return err
@@ -0,0 +1,188 @@
+package top
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/anibalnet/blackbeard/cli/internal/hw"
+	"github.com/anibalnet/blackbeard/cli/internal/ui"
+	"github.com/fatih/color"
+)
+
+const (
+	reverse = "\x1b[7m"
+	reset   = "\x1b[0m"
+)
+
+// splitWidth is the terminal width from which panes are laid out in two
+// columns instead of one.
+const splitWidth = 100
+
+// render lays out a full screen of exactly height lines, each width columns.
+func (m *model) render(width, height int) []string {
+	header := fmt.Sprintf(" flint top · %s", m.cfg.ProjectName)
+	if s := m.sample; s != nil {
+		header += fmt.Sprintf("   cpu %s  mem %.0f%%   %s", avgPct(s.Cores), s.MemPercent, s.At.Format("15:04:05"))
+	}
+
+	var body []string
+	if width >= splitWidth {
+		left := width * 55 / 100
+		right := width - left
+		body = sideBySide(m.containerPane(left, height-2), m.hostPanes(right), left, right)
+	} else {
+		body = append(m.containerPane(width, min(len(m.rows())+4, height/2)), m.hostPanes(width)...)
+	}
+
+	screen := make([]string, 0, height)
+	screen = append(screen, reverse+ui.Fit(header, width)+reset)
+	for i := 0; i < height-2; i++ {
+		line := ""
+		if i < len(body) {
+			line = body[i]
+		}
+		screen = append(screen, ui.Fit(line, width))
+	}
+	screen = append(screen, reverse+ui.Fit(" "+m.footer(), width)+reset)
+	return screen
+}
+
+func (m *model) footer() string {
+	keys := "↑/↓ select  r restart  l logs  q quit"
+	switch {
+	case m.confirm != "":
+		return fmt.Sprintf("Restart %s? y/N", m.confirm)
+	case m.status != "":
+		return m.status + "   " + keys
+	}
+	return keys
+}
+
+// containerPane lists containers, highlighting the selected one.
+func (m *model) containerPane(width, height int) []string {
+	inner := width - 4
+	lines := []string{ui.Fit(fmt.Sprintf("%-20s %7s %18s %6s", "NAME", "CPU", "MEMORY", "MEM%"), inner)}
+
+	switch s := m.sample; {
+	case s == nil:
+		lines = append(lines, "collecting...")
+	case s.ContErr != nil:
+		lines = append(lines, color.RedString("%s", s.ContErr))
+	case len(s.Containers) == 0:
+		lines = append(lines, "no running containers")
+	}
+
+	for i, c := range m.rows() {
+		row := ui.Fit(fmt.Sprintf("%-20s %7s %18s %6s",
+			ui.Fit(c.Name, 20),
+			fmt.Sprintf("%.1f%%", c.CPUPercent),
+			fmt.Sprintf("%s/%s", ui.FormatBytes(c.MemUsage), ui.FormatBytes(c.MemLimit)),
+			fmt.Sprintf("%.0f%%", c.MemPercent)), inner)
+		if i == m.selected {
+			row = reverse + row + reset
+		}
+		lines = append(lines, row)
+	}
+
+	for len(lines) < height-2 {
+		lines = append(lines, "")
+	}
+	return ui.Box("Containers", lines, width)
+}
+
+// hostPanes renders the host CPU, sensors, disk and network panes.
+func (m *model) hostPanes(width int) []string {
+	s := m.sample
+	if s == nil {
+		return ui.Box("Host", []string{"collecting..."}, width)
+	}
+	inner := width - 4
+
+	var cores []string
+	for i, pct := range s.Cores {
+		label := fmt.Sprintf("core%-2d %5.1f%% ", i, pct)
+		cores = append(cores, label+bar(pct, inner-ui.Width(label)))
+	}
+	out := ui.Box("CPU", cores, width)
+
+	var sensors []string
+	var temps []string
+	for _, t := range s.Temps {
+		temps = append(temps, fmt.Sprintf("%s %s", t.Label, hw.FormatTemp(t)))
+	}
+	if len(temps) > 0 {
+		sensors = append(sensors, strings.Join(temps, "   "))
+	}
+	if s.GPU.MaxFreq > 0 {
+		sensors = append(sensors, fmt.Sprintf("Mali %d/%d MHz (%d%%) %s",
+			s.GPU.CurrentFreq/1000000, s.GPU.MaxFreq/1000000, s.GPU.FreqPct, s.GPU.Governor))
+	} else {
+		sensors = append(sensors, "Mali n/a")
+	}
+	sensors = append(sensors, fmt.Sprintf("VPU  %.1f irq/s", s.VPURate))
+	sensors = append(sensors, "Throttled "+hw.FormatThrottle(s.Throttle))
+	for _, r := range s.Throttle.Reasons {
+		sensors = append(sensors, "  "+r)
+	}
+	out = append(out, ui.Box("Sensors", sensors, width)...)
+
+	out = append(out, ui.Box("Disk I/O", rateLines(s.Disks, "read", "write"), width)...)
+	out = append(out, ui.Box("Network", rateLines(s.Nets, "rx", "tx"), width)...)
+	return out
+}
+
+func rateLines(rates []rate, in, out string) []string {
+	if len(rates) == 0 {
+		return []string{"none"}
+	}
+	lines := make([]string, 0, len(rates))
+	for _, r := range rates {
+		lines = append(lines, fmt.Sprintf("%-10s %s %9s/s   %s %9s/s",
+			ui.Fit(r.Name, 10), in, ui.FormatBytes(r.In), out, ui.FormatBytes(r.Out)))
+	}
+	return lines
+}
+
+// sideBySide joins two columns of lines.
+func sideBySide(left, right []string, leftWidth, rightWidth int) []string {
+	n := max(len(left), len(right))
+	out := make([]string, n)
+	for i := range n {
+		l, r := "", ""
+		if i < len(left) {
+			l = left[i]
+		}
+		if i < len(right) {
+			r = right[i]
+		}
+		out[i] = ui.Fit(l, leftWidth) + ui.Fit(r, rightWidth)
+	}
+	return out
+}
+
+// bar draws a usage bar coloured like the CLI's frequency levels.
+func bar(pct float64, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	filled := min(max(int(pct/100*float64(width)+0.5), 0), width)
+	c := color.New(color.FgGreen)
+	switch {
+	case pct >= 75:
+		c = color.New(color.FgRed)
+	case pct >= 50:
+		c = color.New(color.FgYellow)
+	}
+	return c.Sprint(strings.Repeat("█", filled)) + color.New(color.FgHiBlack).Sprint(strings.Repeat("░", width-filled))
+}
+
+func avgPct(values []float64) string {
+	if len(values) == 0 {
+		return "n/a"
+	}
+	var total float64
+	for _, v := range values {
+		total += v
+	}
+	return fmt.Sprintf("%.0f%%", total/float64(len(values)))
+}
@@ -0,0 +1,106 @@
+package top
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/anibalnet/blackbeard/cli/internal/config"
+	dkr "github.com/anibalnet/blackbeard/cli/internal/docker"
+	"github.com/anibalnet/blackbeard/cli/internal/hw"
+	"github.com/anibalnet/blackbeard/cli/internal/stack"
+	"github.com/shirou/gopsutil/v4/cpu"
+	"github.com/shirou/gopsutil/v4/mem"
+	psnet "github.com/shirou/gopsutil/v4/net"
+)
+
+// rate is read/write (or rx/tx) throughput of one device in bytes/s.
+type rate struct {
+	Name string
+	In   float64
+	Out  float64
+}
+
+// sample is one refresh of everything top shows.
+type sample struct {
+	At         time.Time
+	Containers []stack.ContainerUsage
+	ContErr    error
+	Cores      []float64
+	MemPercent float64
+	Temps      []hw.TempReading
+	GPU        hw.GPUInfo
+	Throttle   hw.Throttle
+	VPURate    float64
+	Disks      []rate
+	Nets       []rate
+}
+
+// collector keeps the previous counters needed to turn totals into rates.
+type collector struct {
+	cfg     *config.Config
+	clients *dkr.Clients
+
+	prevAt   time.Time
+	prevVPU  map[string]int64
+	prevDisk map[string]hw.DiskStat
+	prevNet  map[string]psnet.IOCountersStat
+}
+
+func newCollector(cfg *config.Config, clients *dkr.Clients) *collector {
+	c := &collector{cfg: cfg, clients: clients}
+	// Prime counters so the first sample already has rates
+	c.prevAt = time.Now()
+	c.prevVPU = hw.ReadVPUInterrupts()
+	c.prevDisk, _ = hw.ReadDiskStats()
+	c.prevNet, _ = hw.ReadNetCounters()
+	cpu.Percent(0, true)
+	return c
+}
+
+func (c *collector) collect(ctx context.Context) *sample {
+	s := &sample{}
+	s.Containers, s.ContErr = stack.ResourceUsage(ctx, c.cfg, c.clients)
+	sort.Slice(s.Containers, func(i, j int) bool { return s.Containers[i].Name < s.Containers[j].Name })
+
+	now := time.Now()
+	s.At = now
+	elapsed := now.Sub(c.prevAt)
+	secs := max(elapsed.Seconds(), 1e-3)
+	c.prevAt = now
+
+	s.Cores, _ = cpu.Percent(0, true)
+	if vm, err := mem.VirtualMemory(); err == nil {
+		s.MemPercent = vm.UsedPercent
+	}
+
+	for _, t := range hw.ReadTemps() {
+		s.Temps = append(s.Temps, t)
+	}
+	sort.Slice(s.Temps, func(i, j int) bool { return s.Temps[i].Label < s.Temps[j].Label })
+
+	s.GPU = hw.ReadGPUInfo()
+	s.Throttle = hw.ReadThrottle()
+
+	vpu := hw.ReadVPUInterrupts()
+	for _, d := range hw.CalculateVPUDelta(c.prevVPU, vpu, 1) {
+		s.VPURate += float64(max(d.Delta, 0)) / secs
+	}
+	c.prevVPU = vpu
+
+	if disks, err := hw.ReadDiskStats(); err == nil {
+		for _, d := range hw.DiskIODelta(c.prevDisk, disks, elapsed) {
+			s.Disks = append(s.Disks, rate{Name: d.Name, In: d.ReadBps, Out: d.WriteBps})
+		}
+		c.prevDisk = disks
+	}
+
+	if nets, err := hw.ReadNetCounters(); err == nil {
+		for _, n := range hw.NetDelta(c.prevNet, nets, elapsed) {
+			s.Nets = append(s.Nets, rate{Name: n.Name, In: n.RxBps, Out: n.TxBps})
+		}
+		c.prevNet = nets
+	}
+
+	return s
+}
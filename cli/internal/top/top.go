@@ -0,0 +1,283 @@
+package top
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/anibalnet/blackbeard/cli/internal/config"
+	dkr "github.com/anibalnet/blackbeard/cli/internal/docker"
+	"github.com/anibalnet/blackbeard/cli/internal/service"
+	"github.com/anibalnet/blackbeard/cli/internal/stack"
+	"github.com/anibalnet/blackbeard/cli/internal/ui"
+	"golang.org/x/term"
+)
+
+// Terminal control sequences.
+const (
+	enterScreen = "\x1b[?1049h\x1b[?25l" // alternate screen, hide cursor
+	leaveScreen = "\x1b[?25h\x1b[?1049l"
+	home        = "\x1b[H"
+)
+
+// Keys decoded from raw terminal input.
+const (
+	keyUp = iota + 256
+	keyDown
+)
+
+// model is the state of the dashboard between redraws.
+type model struct {
+	cfg      *config.Config
+	svc      *service.Service
+	sample   *sample
+	selected int
+	confirm  string // service awaiting restart confirmation
+	status   string
+	job      string // ID of the restart job being followed
+}
+
+func (m *model) rows() []stack.ContainerUsage {
+	if m.sample == nil {
+		return nil
+	}
+	return m.sample.Containers
+}
+
+// RunTop runs the full-screen dashboard until q or Ctrl+C.
+func RunTop(ctx context.Context, cfg *config.Config, clients *dkr.Clients, p *ui.Printer, interval time.Duration) error {
+	in, out := int(os.Stdin.Fd()), int(os.Stdout.Fd())
+	if !term.IsTerminal(in) || !term.IsTerminal(out) {
+		return errors.New("flint top needs an interactive terminal")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	m := &model{cfg: cfg, svc: service.New(cfg, clients)}
+	samples := make(chan *sample, 1)
+	go func() {
+		c := newCollector(cfg, clients)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			s := c.collect(ctx)
+			select {
+			case samples <- s:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	keys := make(chan int, 16)
+	go readKeys(keys)
+
+	winch := make(chan os.Signal, 1)
+	signal.Notify(winch, syscall.SIGWINCH)
+	defer signal.Stop(winch)
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGTERM, syscall.SIGHUP)
+	defer signal.Stop(quit)
+
+	restore, err := enter(in)
+	if err != nil {
+		return err
+	}
+	defer func() { restore() }()
+
+	jobTick := time.NewTicker(time.Second)
+	defer jobTick.Stop()
+
+	for {
+		draw(m, out)
+
+		select {
+		case s := <-samples:
+			m.sample = s
+			m.selected = min(m.selected, max(len(s.Containers)-1, 0))
+		case <-winch:
+		case <-quit:
+			return nil
+		case <-jobTick.C:
+			m.followJob()
+		case k := <-keys:
+			switch m.handleKey(k) {
+			case actionQuit:
+				return nil
+			case actionLogs:
+				restore()
+				m.tailLogs(ctx, clients, p)
+				if restore, err = enter(in); err != nil {
+					return err
+				}
+				drainKeys(keys)
+			}
+		}
+	}
+}
+
+type action int
+
+const (
+	actionNone action = iota
+	actionQuit
+	actionLogs
+)
+
+func (m *model) handleKey(k int) action {
+	if m.confirm != "" {
+		if k == 'y' || k == 'Y' {
+			m.restart(m.confirm)
+		} else {
+			m.status = "restart cancelled"
+		}
+		m.confirm = ""
+		return actionNone
+	}
+
+	switch k {
+	case 'q', 3: // 3 is Ctrl+C in raw mode
+		return actionQuit
+	case keyUp, 'k':
+		m.selected = max(m.selected-1, 0)
+	case keyDown, 'j':
+		m.selected = min(m.selected+1, max(len(m.rows())-1, 0))
+	case 'r':
+		if svc := m.selectedService(); svc != "" {
+			m.confirm = svc
+		}
+	case 'l':
+		if m.selectedService() != "" {
+			return actionLogs
+		}
+	}
+	return actionNone
+}
+
+func (m *model) selectedService() string {
+	rows := m.rows()
+	if m.selected >= len(rows) {
+		return ""
+	}
+	if rows[m.selected].Service != "" {
+		return rows[m.selected].Service
+	}
+	return rows[m.selected].Name
+}
+
+// restart runs through the service layer so it is audited like the CLI.
+func (m *model) restart(svc string) {
+	job, err := m.svc.Start(service.Request{Kind: service.KindRestart, Service: svc}, "flint top")
+	if err != nil {
+		m.status = "✗ " + err.Error()
+		return
+	}
+	m.job = job.ID
+	m.status = fmt.Sprintf("restarting %s...", svc)
+}
+
+func (m *model) followJob() {
+	if m.job == "" {
+		return
+	}
+	job, _, err := m.svc.Job(m.job, 0)
+	if err != nil || job.Running() {
+		return
+	}
+	if job.Error != "" {
+		m.status = "✗ " + job.Error
+	} else {
+		m.status = fmt.Sprintf("✓ %s restarted", job.Service)
+	}
+	m.job = ""
+}
+
+func (m *model) tailLogs(ctx context.Context, clients *dkr.Clients, p *ui.Printer) {
+	svc := m.selectedService()
+	p.Info(fmt.Sprintf("Logs for %s (Ctrl+C to return)", svc))
+	if err := stack.RunLogs(ctx, m.cfg, clients, p, svc); err != nil && ctx.Err() == nil {
+		m.status = "✗ logs: " + err.Error()
+	}
+}
+
+// enter switches to raw mode on the alternate screen and returns the
+// function that undoes it.
+func enter(fd int) (func(), error) {
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return nil, fmt.Errorf("entering raw mode: %w", err)
+	}
+	os.Stdout.WriteString(enterScreen)
+	done := false
+	return func() {
+		if done {
+			return
+		}
+		done = true
+		os.Stdout.WriteString(leaveScreen)
+		term.Restore(fd, state)
+	}, nil
+}
+
+func draw(m *model, fd int) {
+	width, height, err := term.GetSize(fd)
+	if err != nil || width < 20 || height < 5 {
+		return
+	}
+	var b strings.Builder
+	b.WriteString(home)
+	for i, line := range m.render(width, height) {
+		if i > 0 {
+			b.WriteString("\r\n")
+		}
+		b.WriteString(line)
+	}
+	os.Stdout.WriteString(b.String())
+}
+
+// readKeys decodes raw stdin bytes into keys, including arrow sequences.
+func readKeys(keys chan<- int) {
+	buf := make([]byte, 32)
+	for {
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			close(keys)
+			return
+		}
+		for i := 0; i < n; i++ {
+			if buf[i] == 0x1b && i+2 < n && buf[i+1] == '[' {
+				switch buf[i+2] {
+				case 'A':
+					keys <- keyUp
+				case 'B':
+					keys <- keyDown
+				}
+				i += 2
+				continue
+			}
+			keys <- int(buf[i])
+		}
+	}
+}
+
+// drainKeys drops input typed while the logs were shown.
+func drainKeys(keys <-chan int) {
+	for {
+		select {
+		case <-keys:
+		default:
+			return
+		}
+	}
+}
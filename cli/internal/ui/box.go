@@ -0,0 +1,50 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/acarl005/stripansi"
+	"github.com/mattn/go-runewidth"
+)
+
+// Width returns the number of terminal columns s occupies, ignoring ANSI
+// colour codes and counting wide runes as two.
+func Width(s string) int {
+	return runewidth.StringWidth(stripansi.Strip(s))
+}
+
+// Fit pads s with spaces or truncates it to exactly w columns. Truncated
+// strings lose their colour codes.
+func Fit(s string, w int) string {
+	if w <= 0 {
+		return ""
+	}
+	width := Width(s)
+	switch {
+	case width == w:
+		return s
+	case width < w:
+		return s + strings.Repeat(" ", w-width)
+	}
+	return runewidth.FillRight(runewidth.Truncate(stripansi.Strip(s), w, "…"), w)
+}
+
+// Box frames lines in a single-line border of the given total width, with
+// title set into the top edge. Content is padded or truncated to fit.
+func Box(title string, lines []string, width int) []string {
+	inner := max(width-4, 1)
+
+	top := "┌─"
+	if title != "" {
+		top += " " + title + " "
+	}
+	top = Fit(top+strings.Repeat("─", max(width-1-Width(top), 0)), width-1) + "┐"
+
+	out := make([]string, 0, len(lines)+2)
+	out = append(out, top)
+	for _, l := range lines {
+		out = append(out, "│ "+Fit(l, inner)+" │")
+	}
+	out = append(out, "└"+strings.Repeat("─", max(width-2, 0))+"┘")
+	return out
+}
@@ -0,0 +1,49 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FormatBytes formats a byte count in 1024-based units, e.g. "1.5GiB".
+// Negative values print as 0B.
+func FormatBytes[T int | int64 | uint64 | float64](n T) string {
+	return formatBytes(float64(n), "")
+}
+
+// FormatBytesSpaced is FormatBytes with a space before the unit, e.g.
+// "1.5 GiB", as the hardware views and the dashboard print sizes.
+func FormatBytesSpaced[T int | int64 | uint64 | float64](n T) string {
+	return formatBytes(float64(n), " ")
+}
+
+func formatBytes(b float64, sep string) string {
+	b = max(b, 0)
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%.0f%sB", b, sep)
+	}
+	exp := 0
+	for b >= unit*unit && exp < 5 {
+		b /= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%s%ciB", b/unit, sep, "KMGTPE"[exp])
+}
+
+// ParseSince parses a look-back period; besides Go durations it accepts
+// whole days such as "7d".
+func ParseSince(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n > 0 {
+			return time.Duration(n) * 24 * time.Hour, nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid duration %q (e.g. 30m, 24h, 7d)", s)
+	}
+	return d, nil
+}
@@ -0,0 +1,50 @@
+package ui
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSince(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+	}{
+		{"30m", 30 * time.Minute},
+		{"24h", 24 * time.Hour},
+		{"7d", 7 * 24 * time.Hour},
+		{"1h30m", 90 * time.Minute},
+	}
+	for _, tt := range tests {
+		got, err := ParseSince(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseSince(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+
+	for _, in := range []string{"", "0d", "-1d", "0s", "-5m", "d", "week"} {
+		if got, err := ParseSince(in); err == nil {
+			t.Errorf("ParseSince(%q) = %v, want an error", in, got)
+		}
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		got, want string
+	}{
+		{FormatBytes(0), "0B"},
+		{FormatBytes(-5), "0B"},
+		{FormatBytes(1023), "1023B"},
+		{FormatBytes(int64(1536)), "1.5KiB"},
+		{FormatBytes(uint64(3) << 30), "3.0GiB"},
+		{FormatBytes(float64(1 << 40)), "1.0TiB"},
+		{FormatBytesSpaced(512), "512 B"},
+		{FormatBytesSpaced(int64(5) << 20), "5.0 MiB"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("FormatBytes = %q, want %q", tt.got, tt.want)
+		}
+	}
+}
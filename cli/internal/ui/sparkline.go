@@ -0,0 +1,26 @@
+package ui
+
+import (
+	"math"
+	"strings"
+)
+
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline draws values as block characters scaled between lo and hi.
+// NaN values are drawn as gaps.
+func Sparkline(values []float64, lo, hi float64) string {
+	var b strings.Builder
+	for _, v := range values {
+		if math.IsNaN(v) {
+			b.WriteRune(' ')
+			continue
+		}
+		i := 0
+		if hi > lo {
+			i = int((v - lo) / (hi - lo) * float64(len(sparkBlocks)-1))
+		}
+		b.WriteRune(sparkBlocks[max(0, min(i, len(sparkBlocks)-1))])
+	}
+	return b.String()
+}